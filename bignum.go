@@ -0,0 +1,349 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Arbitrary precision numbers are represented at runtime by *big.Int (BigInt) and
+// *big.Rat (Decimal). Both mix freely with int64 values, a BigInt combined with a
+// Decimal yields a Decimal, but mixing either one with a float64 is an error since it
+// would silently lose the precision they exist for.
+
+// decimalPrecision is the number of fractional digits printed for decimals which have
+// no finite decimal expansion, like Decimal(1) / Decimal(3).
+const decimalPrecision = 20
+
+func isBigNumber(val interface{}) bool {
+	switch val.(type) {
+	case *big.Int, *big.Rat:
+		return true
+	}
+
+	return false
+}
+
+func toBigInt(val interface{}) (*big.Int, bool) {
+	switch v := val.(type) {
+	case int64:
+		return big.NewInt(v), true
+	case *big.Int:
+		return v, true
+	}
+
+	return nil, false
+}
+
+func toRat(val interface{}) (*big.Rat, bool) {
+	switch v := val.(type) {
+	case int64:
+		return new(big.Rat).SetInt64(v), true
+	case *big.Int:
+		return new(big.Rat).SetInt(v), true
+	case *big.Rat:
+		return v, true
+	}
+
+	return nil, false
+}
+
+// checkBigOperands validates the operands of an arithmetic or comparison operator when
+// at least one of them is a BigInt or a Decimal.
+func checkBigOperands(leftVal, rightVal interface{}, line int) error {
+	for _, v := range []interface{}{leftVal, rightVal} {
+		if _, ok := v.(float64); ok {
+			return fmt.Errorf("Cannot mix %s and float numbers, convert one of them explicitly.\n[line %d]", bigTypeName(leftVal, rightVal), line)
+		}
+
+		if !isNumber(v) && !isBigNumber(v) {
+			return fmt.Errorf("Operands must be numbers.\n[line %d]", line)
+		}
+	}
+
+	return nil
+}
+
+func bigTypeName(leftVal, rightVal interface{}) string {
+	_, ok := leftVal.(*big.Rat)
+	_, ok2 := rightVal.(*big.Rat)
+	if ok || ok2 {
+		return "Decimal"
+	}
+
+	return "BigInt"
+}
+
+func bigArithmetic(operator TokenType, leftVal, rightVal interface{}, line int) (interface{}, error) {
+	if err := checkBigOperands(leftVal, rightVal, line); err != nil {
+		return nil, err
+	}
+
+	lv, ok := toBigInt(leftVal)
+	rv, ok2 := toBigInt(rightVal)
+	if ok && ok2 {
+		switch operator {
+		case PLUS:
+			return new(big.Int).Add(lv, rv), nil
+		case MINUS:
+			return new(big.Int).Sub(lv, rv), nil
+		case STAR:
+			return new(big.Int).Mul(lv, rv), nil
+		case SLASH:
+			if rv.Sign() == 0 {
+				return nil, fmt.Errorf("Division by zero.\n[line %d]", line)
+			}
+
+			return new(big.Int).Quo(lv, rv), nil
+		case PERCENT:
+			if rv.Sign() == 0 {
+				return nil, fmt.Errorf("Division by zero.\n[line %d]", line)
+			}
+
+			return new(big.Int).Rem(lv, rv), nil
+		}
+	}
+
+	lr, _ := toRat(leftVal)
+	rr, _ := toRat(rightVal)
+
+	switch operator {
+	case PLUS:
+		return new(big.Rat).Add(lr, rr), nil
+	case MINUS:
+		return new(big.Rat).Sub(lr, rr), nil
+	case STAR:
+		return new(big.Rat).Mul(lr, rr), nil
+	case SLASH:
+		if rr.Sign() == 0 {
+			return nil, fmt.Errorf("Division by zero.\n[line %d]", line)
+		}
+
+		return new(big.Rat).Quo(lr, rr), nil
+	case PERCENT:
+		if rr.Sign() == 0 {
+			return nil, fmt.Errorf("Division by zero.\n[line %d]", line)
+		}
+
+		// like for integers the remainder has the sign of the dividend, the quotient
+		// being truncated towards zero
+		q := new(big.Int).Quo(
+			new(big.Int).Mul(lr.Num(), rr.Denom()),
+			new(big.Int).Mul(rr.Num(), lr.Denom()),
+		)
+
+		return new(big.Rat).Sub(lr, new(big.Rat).Mul(rr, new(big.Rat).SetInt(q))), nil
+	}
+
+	// unreachable
+	return nil, nil
+}
+
+func bigCompare(operator TokenType, leftVal, rightVal interface{}, line int) (interface{}, error) {
+	if err := checkBigOperands(leftVal, rightVal, line); err != nil {
+		return nil, err
+	}
+
+	lr, _ := toRat(leftVal)
+	rr, _ := toRat(rightVal)
+	cmp := lr.Cmp(rr)
+
+	switch operator {
+	case LESS:
+		return cmp < 0, nil
+	case LESS_EQUAL:
+		return cmp <= 0, nil
+	case GREATER:
+		return cmp > 0, nil
+	case GREATER_EQUAL:
+		return cmp >= 0, nil
+	}
+
+	// unreachable
+	return nil, nil
+}
+
+func negateBigNumber(val interface{}) interface{} {
+	switch v := val.(type) {
+	case *big.Int:
+		return new(big.Int).Neg(v)
+	case *big.Rat:
+		return new(big.Rat).Neg(v)
+	}
+
+	return nil
+}
+
+// isBigEqual compares a BigInt or a Decimal against any other number by value.
+func isBigEqual(leftVal, rightVal interface{}) bool {
+	lr, ok := exactRat(leftVal)
+	rr, ok2 := exactRat(rightVal)
+
+	return ok && ok2 && lr.Cmp(rr) == 0
+}
+
+func exactRat(val interface{}) (*big.Rat, bool) {
+	if v, ok := val.(float64); ok {
+		if math.IsInf(v, 0) || math.IsNaN(v) {
+			return nil, false
+		}
+
+		return new(big.Rat).SetFloat64(v), true
+	}
+
+	return toRat(val)
+}
+
+func formatDecimal(r *big.Rat) string {
+	if r.IsInt() {
+		return r.Num().String()
+	}
+
+	// a fraction has a finite decimal expansion only when its denominator has no prime
+	// factors other than 2 and 5, the expansion then needs as many digits as the
+	// larger of the two exponents.
+	denom := new(big.Int).Set(r.Denom())
+	digits := 0
+
+	for _, factor := range []int64{2, 5} {
+		f := big.NewInt(factor)
+		count := 0
+
+		for new(big.Int).Rem(denom, f).Sign() == 0 {
+			denom.Quo(denom, f)
+			count++
+		}
+
+		digits = max(digits, count)
+	}
+
+	if denom.Cmp(big.NewInt(1)) != 0 {
+		s := r.FloatString(decimalPrecision)
+		return strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+
+	return r.FloatString(digits)
+}
+
+// NativeBigInt converts numbers and strings to a BigInt.
+type NativeBigInt struct{}
+
+func (nb *NativeBigInt) Call(args ...interface{}) (interface{}, error) {
+	switch v := args[0].(type) {
+	case int64:
+		return big.NewInt(v), nil
+	case *big.Int:
+		return v, nil
+	case float64:
+		if v != math.Trunc(v) || math.IsInf(v, 0) {
+			return nil, fmt.Errorf("Cannot convert %s to BigInt.", formatNumber(v))
+		}
+
+		i, _ := new(big.Float).SetFloat64(v).Int(nil)
+		return i, nil
+	case *big.Rat:
+		if !v.IsInt() {
+			return nil, fmt.Errorf("Cannot convert %s to BigInt.", formatDecimal(v))
+		}
+
+		return new(big.Int).Set(v.Num()), nil
+	case string:
+		i, ok := new(big.Int).SetString(strings.ReplaceAll(v, "_", ""), 0)
+		if !ok {
+			return nil, fmt.Errorf("Cannot convert \"%s\" to BigInt.", v)
+		}
+
+		return i, nil
+	}
+
+	return nil, errors.New("BigInt() expects a number or a string.")
+}
+
+func (nb *NativeBigInt) Arity() (int, int) { return 1, 1 }
+
+func (nb *NativeBigInt) String() string {
+	return "<native fn>"
+}
+
+// NativeDecimal converts numbers and strings to a Decimal. Floats are converted
+// through their shortest decimal representation so Decimal(0.1) is exactly 0.1.
+type NativeDecimal struct{}
+
+func (nd *NativeDecimal) Call(args ...interface{}) (interface{}, error) {
+	switch v := args[0].(type) {
+	case int64, *big.Int, *big.Rat:
+		r, _ := toRat(v)
+		return r, nil
+	case float64:
+		if math.IsInf(v, 0) || math.IsNaN(v) {
+			return nil, fmt.Errorf("Cannot convert %s to Decimal.", formatNumber(v))
+		}
+
+		r, _ := new(big.Rat).SetString(strconv.FormatFloat(v, 'g', -1, 64))
+		return r, nil
+	case string:
+		r, ok := new(big.Rat).SetString(strings.ReplaceAll(v, "_", ""))
+		if !ok {
+			return nil, fmt.Errorf("Cannot convert \"%s\" to Decimal.", v)
+		}
+
+		return r, nil
+	}
+
+	return nil, errors.New("Decimal() expects a number or a string.")
+}
+
+func (nd *NativeDecimal) Arity() (int, int) { return 1, 1 }
+
+func (nd *NativeDecimal) String() string {
+	return "<native fn>"
+}
+
+// NativeNumber converts a BigInt or a Decimal back to a plain number, which may lose
+// precision. Integral values that fit in an int64 stay integers.
+type NativeNumber struct{}
+
+func (nn *NativeNumber) Call(args ...interface{}) (interface{}, error) {
+	switch v := args[0].(type) {
+	case int64, float64:
+		return v, nil
+	case *big.Int:
+		if v.IsInt64() {
+			return v.Int64(), nil
+		}
+
+		f, _ := new(big.Float).SetInt(v).Float64()
+		return f, nil
+	case *big.Rat:
+		if v.IsInt() && v.Num().IsInt64() {
+			return v.Num().Int64(), nil
+		}
+
+		f, _ := v.Float64()
+		return f, nil
+	case string:
+		digits, negative := strings.CutPrefix(strings.TrimSpace(v), "-")
+
+		n, ok := parseNumber(digits)
+		if ok && isNumber(n) {
+			if negative {
+				return negateNumber(n), nil
+			}
+
+			return n, nil
+		}
+
+		return nil, fmt.Errorf("Cannot convert \"%s\" to a number.", v)
+	}
+
+	return nil, errors.New("Number() expects a number or a string.")
+}
+
+func (nn *NativeNumber) Arity() (int, int) { return 1, 1 }
+
+func (nn *NativeNumber) String() string {
+	return "<native fn>"
+}
@@ -0,0 +1,1039 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+)
+
+// The check command finds type errors without running the program. Types come from the
+// optional annotations on variables, parameters, return values and class fields, and
+// are inferred locally for everything else: literals, operators and calls of the
+// functions and classes the program declares. Whatever the checker cannot tell the type
+// of is not checked, so it only reports what is certain to fail when it runs.
+
+// TypeAnnotation is the ": type" annotation of a variable, a parameter, a return value
+// or a field. The evaluator ignores annotations. A trailing '?' makes the type nilable.
+type TypeAnnotation struct {
+	Name    string
+	Nilable bool
+	Line    int
+}
+
+func (t *TypeAnnotation) String() string {
+	if t.Nilable {
+		return t.Name + "?"
+	}
+
+	return t.Name
+}
+
+// FieldDecl declares the type of a field of the instances of a class.
+type FieldDecl struct {
+	Name string
+	Type *TypeAnnotation
+}
+
+// staticType is the type of an expression as far as the checker can tell, nil when it
+// cannot. Kind is what typeof() would return for the value, Name is the class of an
+// instance or the enum of an enum value.
+type staticType struct {
+	Kind    string
+	Name    string
+	Nilable bool
+
+	// Fun is the declaration of a function or of the init method of a class, when
+	// known, Class the class of a class or an instance and Enum the enum of an enum or
+	// an enum value.
+	Fun   *FunDeclStmt
+	Class *classInfo
+	Enum  *EnumDeclStmt
+}
+
+func (t *staticType) String() string {
+	name := t.Kind
+	if t.Name != "" {
+		name = t.Name
+	}
+
+	if t.Nilable {
+		return name + "?"
+	}
+
+	return name
+}
+
+// annotationTypes are the type names annotations accept besides classes and enums.
+var annotationTypes = []string{
+	"number", "string", "bool", "nil", "list", "function", "class", "range", "generator",
+	"task", "channel",
+}
+
+type classInfo struct {
+	Decl       *ClassDeclStmt
+	SuperClass *classInfo
+}
+
+// method returns the method called name of the instances of the class, looking up the
+// superclasses as well.
+func (ci *classInfo) method(name string) (*FunDeclStmt, bool) {
+	for curr := ci; curr != nil; curr = curr.SuperClass {
+		for _, m := range curr.Decl.Methods {
+			if m.Name == name {
+				return m, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// field returns the declared type of a field of the instances of the class.
+func (ci *classInfo) field(name string) (*TypeAnnotation, bool) {
+	for curr := ci; curr != nil; curr = curr.SuperClass {
+		for _, f := range curr.Decl.Fields {
+			if f.Name == name {
+				return f.Type, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// isSubclassOf reports whether ci is other or one of its subclasses. Classes extending
+// a class the checker does not know might be subclasses of anything.
+func (ci *classInfo) isSubclassOf(other *classInfo) bool {
+	for curr := ci; ; curr = curr.SuperClass {
+		if curr == other {
+			return true
+		}
+
+		if curr.SuperClass == nil {
+			return curr.Decl.SuperClass != nil
+		}
+	}
+}
+
+type checkVariable struct {
+	Type *staticType
+
+	// Declared is the annotation of the variable, assignments must respect it.
+	Declared *TypeAnnotation
+
+	decl declaration
+}
+
+// declaration identifies a variable by the node declaring it, name tells apart the
+// variables a single node like an object pattern declares.
+type declaration struct {
+	node interface{}
+	name string
+}
+
+type Checker struct {
+	scopes []map[string]*checkVariable
+	errors []error
+
+	// assigned holds the variables the program assigns to after their declaration,
+	// their initial type is not trusted since it might change.
+	assigned map[declaration]bool
+
+	// assignedNames holds the names assigned to where no variable is declared yet, a
+	// function can assign to a variable declared after it.
+	assignedNames map[string]bool
+
+	// hoisted holds the top level classes and enums, which annotations can name before
+	// they are declared.
+	hoisted map[string]*staticType
+
+	// topLevel holds the names of the top level declarations.
+	topLevel map[string]bool
+
+	// function is the function whose body is checked.
+	function *FunDeclStmt
+}
+
+func NewChecker() *Checker {
+	return &Checker{
+		scopes:        []map[string]*checkVariable{{}},
+		assigned:      make(map[declaration]bool),
+		assignedNames: make(map[string]bool),
+		hoisted:       make(map[string]*staticType),
+		topLevel:      make(map[string]bool),
+	}
+}
+
+// Check type checks a program and returns the errors found, in order.
+func (c *Checker) Check(stmts []Statement) []error {
+	// a first pass finds the variables which are assigned to anywhere
+	c.collectAssigned(stmts)
+
+	for _, stmt := range stmts {
+		c.declareHoisted(stmt)
+	}
+
+	for _, stmt := range stmts {
+		c.checkStmt(stmt)
+	}
+
+	return c.errors
+}
+
+func (c *Checker) collectAssigned(stmts []Statement) {
+	collector := NewChecker()
+	collector.assigned = c.assigned
+	collector.assignedNames = c.assignedNames
+
+	for _, stmt := range stmts {
+		collector.checkStmt(stmt)
+	}
+}
+
+// declareHoisted records the top level declarations before checking anything. Like the
+// interpreter, the checker only declares them when it reaches them, but annotations can
+// name the classes and enums declared after them.
+func (c *Checker) declareHoisted(stmt Statement) {
+	switch s := stmt.(type) {
+	case *VarDeclStmt:
+		c.topLevel[s.Name] = true
+	case *FunDeclStmt:
+		c.topLevel[s.Name] = true
+	case *ClassDeclStmt:
+		c.topLevel[s.Name] = true
+		c.hoisted[s.Name] = c.classType(s)
+	case *TraitDeclStmt:
+		c.topLevel[s.Name] = true
+	case *InterfaceDeclStmt:
+		c.topLevel[s.Name] = true
+	case *EnumDeclStmt:
+		c.topLevel[s.Name] = true
+		c.hoisted[s.Name] = &staticType{Kind: "enum", Name: s.Name, Enum: s}
+	}
+}
+
+func (c *Checker) errorf(line int, format string, args ...interface{}) {
+	c.errors = append(c.errors, fmt.Errorf("[line %d] Error: %s", line, fmt.Sprintf(format, args...)))
+}
+
+func (c *Checker) beginScope() {
+	c.scopes = append(c.scopes, map[string]*checkVariable{})
+}
+
+func (c *Checker) endScope() {
+	c.scopes = c.scopes[:len(c.scopes)-1]
+}
+
+// declare declares the variable called name, node is what declares it.
+func (c *Checker) declare(node interface{}, name string, t *staticType, declared *TypeAnnotation) {
+	c.scopes[len(c.scopes)-1][name] = &checkVariable{Type: t, Declared: declared, decl: c.declarationOf(node, name)}
+}
+
+// declarationOf identifies the variable called name which node declares. Declaring a
+// name again in the same scope reuses the variable, like the interpreter does.
+func (c *Checker) declarationOf(node interface{}, name string) declaration {
+	if v, ok := c.scopes[len(c.scopes)-1][name]; ok {
+		return v.decl
+	}
+
+	return declaration{node, name}
+}
+
+func (c *Checker) lookup(name string) (*checkVariable, bool) {
+	for i := len(c.scopes) - 1; i >= 0; i-- {
+		if v, ok := c.scopes[i][name]; ok {
+			return v, true
+		}
+	}
+
+	return nil, false
+}
+
+// isAssigned reports whether the variable called name which node declares is assigned
+// to after its declaration.
+func (c *Checker) isAssigned(node interface{}, name string) bool {
+	return c.assigned[c.declarationOf(node, name)] || c.assignedNames[name]
+}
+
+// markAssignedName records an assignment to the variable called name.
+func (c *Checker) markAssignedName(name string) {
+	if v, ok := c.lookup(name); ok {
+		c.assigned[v.decl] = true
+	} else {
+		c.assignedNames[name] = true
+	}
+}
+
+// typeNamed returns the type of the variable an annotation names.
+func (c *Checker) typeNamed(name string) *staticType {
+	if v, ok := c.lookup(name); ok {
+		return v.Type
+	}
+
+	return c.hoisted[name]
+}
+
+// resolve turns an annotation into a type, nil stands for any type.
+func (c *Checker) resolve(t *TypeAnnotation) *staticType {
+	if t == nil || t.Name == "any" {
+		return nil
+	}
+
+	if slices.Contains(annotationTypes, t.Name) {
+		return &staticType{Kind: t.Name, Nilable: t.Nilable}
+	}
+
+	if named := c.typeNamed(t.Name); named != nil {
+		switch named.Kind {
+		case "class":
+			return &staticType{Kind: "instance", Name: t.Name, Nilable: t.Nilable, Class: named.Class}
+		case "enum":
+			return &staticType{Kind: "enum value", Name: t.Name, Nilable: t.Nilable, Enum: named.Enum}
+		case "trait", "interface":
+			return nil
+		}
+	}
+
+	c.errorf(t.Line, "Unknown type '%s'.", t.Name)
+
+	return nil
+}
+
+// assignable reports whether a value of type from can be used where a value of type to
+// is expected.
+func assignable(to, from *staticType) bool {
+	if to == nil || from == nil {
+		return true
+	}
+
+	if from.Kind == "nil" {
+		return to.Nilable || to.Kind == "nil"
+	}
+
+	switch to.Kind {
+	case "instance":
+		return from.Kind == "instance" && from.Class.isSubclassOf(to.Class)
+	case "enum value":
+		return from.Kind == "enum value" && from.Enum == to.Enum
+	case "function":
+		return from.Kind == "function" || from.Kind == "class"
+	}
+
+	return to.Kind == from.Kind
+}
+
+func (c *Checker) funType(f *FunDeclStmt) *staticType {
+	if c.isAssigned(f, f.Name) {
+		return nil
+	}
+
+	return &staticType{Kind: "function", Fun: f}
+}
+
+// declareClass declares a class, with the type it was given when hoisting top level
+// declarations if it is one of them.
+func (c *Checker) declareClass(decl *ClassDeclStmt) *staticType {
+	t, ok := c.hoisted[decl.Name]
+	if !ok || t.Class.Decl != decl {
+		t = c.classType(decl)
+	}
+
+	c.declare(decl, decl.Name, t, nil)
+
+	return t
+}
+
+func (c *Checker) classType(decl *ClassDeclStmt) *staticType {
+	info := classInfo{Decl: decl}
+
+	if decl.SuperClass != nil {
+		if sc := c.typeNamed(decl.SuperClass.Name); sc != nil && sc.Class != nil {
+			info.SuperClass = sc.Class
+		}
+	}
+
+	init, _ := info.method("init")
+
+	return &staticType{Kind: "class", Name: decl.Name, Class: &info, Fun: init}
+}
+
+func (c *Checker) checkStmt(stmt Statement) {
+	switch s := stmt.(type) {
+	case *ExprStmt:
+		c.checkExpr(s.Expr)
+	case *PrintStmt:
+		c.checkExpr(s.Expr)
+	case *VarDeclStmt:
+		var t *staticType
+		if s.Expr != nil {
+			t = c.checkExpr(s.Expr)
+		}
+
+		// a variable declared without an initializer is nil until it is assigned to
+		declared := c.resolve(s.Type)
+		if s.Expr != nil && !assignable(declared, t) {
+			c.errorf(s.Type.Line, "Variable '%s' must be %v, got %v.", s.Name, s.Type, t)
+		}
+
+		switch {
+		case declared != nil:
+			t = declared
+		case c.isAssigned(s, s.Name) && !s.Const:
+			t = nil
+		}
+
+		c.declare(s, s.Name, t, s.Type)
+	case *DestructureDeclStmt:
+		c.checkExpr(s.Expr)
+		c.declareTarget(s.Target)
+	case *FunDeclStmt:
+		c.declare(s, s.Name, c.funType(s), nil)
+		c.checkFunction(s)
+	case *ClassDeclStmt:
+		c.checkClass(s)
+	case *TraitDeclStmt:
+		c.declare(s, s.Name, &staticType{Kind: "trait"}, nil)
+
+		for _, m := range slices.Concat(s.Methods, s.Getters, s.Setters) {
+			c.checkFunction(m)
+		}
+	case *InterfaceDeclStmt:
+		c.declare(s, s.Name, &staticType{Kind: "interface"}, nil)
+	case *EnumDeclStmt:
+		c.declare(s, s.Name, &staticType{Kind: "enum", Name: s.Name, Enum: s}, nil)
+	case *BlockStmt:
+		c.beginScope()
+		defer c.endScope()
+
+		for _, stmt := range s.Stmts {
+			c.checkStmt(stmt)
+		}
+	case *IfStmt:
+		c.checkExpr(s.Condition)
+		c.checkScoped(s.Then)
+
+		if s.Else != nil {
+			c.checkScoped(s.Else)
+		}
+	case *WhileStmt:
+		c.checkExpr(s.Condition)
+		c.checkScoped(s.Body)
+	case *ForInStmt:
+		iterable := c.checkExpr(s.Iterable)
+
+		if iterable != nil {
+			switch iterable.Kind {
+			case "number", "bool", "nil", "function", "class", "enum", "enum value", "task":
+				c.errorf(s.Line, "Cannot iterate over %v.", iterable)
+			}
+		}
+
+		c.beginScope()
+		defer c.endScope()
+
+		if id, ok := s.Target.(*IdentifierExpr); ok && iterable != nil && iterable.Kind == "range" && !c.isAssigned(id, id.Name) {
+			c.declare(id, id.Name, &staticType{Kind: "number"}, nil)
+		} else {
+			c.declareTarget(s.Target)
+		}
+
+		c.checkStmt(s.Body)
+	case *ReturnStmt:
+		t := c.checkExpr(s.Expr)
+
+		f := c.function
+		if f == nil || f.ReturnType == nil || f.Generator || f.Name == "init" {
+			return
+		}
+
+		if !assignable(c.resolve(f.ReturnType), t) {
+			c.errorf(s.Line, "%s must return %v, got %v.", f.Name, f.ReturnType, t)
+		}
+	case *YieldStmt:
+		c.checkExpr(s.Expr)
+	case *MatchStmt:
+		c.checkExpr(s.Subject)
+		c.checkMatchCases(s.Cases)
+	case *SelectStmt:
+		for _, sc := range s.Cases {
+			c.checkExpr(sc.Channel)
+
+			if sc.Value != nil {
+				c.checkExpr(sc.Value)
+			}
+
+			c.beginScope()
+
+			if sc.Name != "" {
+				c.declare(sc, sc.Name, nil, nil)
+			}
+
+			c.checkStmt(sc.Body)
+			c.endScope()
+		}
+
+		if s.Default != nil {
+			c.checkScoped(s.Default)
+		}
+	}
+}
+
+// checkScoped checks the body of a statement, which gets a scope of its own.
+func (c *Checker) checkScoped(stmt Statement) {
+	c.beginScope()
+	defer c.endScope()
+
+	c.checkStmt(stmt)
+}
+
+func (c *Checker) declareTarget(target Expression) {
+	switch t := target.(type) {
+	case *IdentifierExpr:
+		c.declare(t, t.Name, nil, nil)
+	case *ListExpr:
+		for _, e := range t.Elements {
+			c.declareTarget(e)
+		}
+	case *SpreadExpr:
+		c.declareTarget(t.Expr)
+	case *ObjectPattern:
+		for _, name := range t.Names {
+			c.declare(t, name, nil, nil)
+		}
+	}
+}
+
+func (c *Checker) checkFunction(f *FunDeclStmt) {
+	c.beginScope()
+	defer c.endScope()
+
+	for i, param := range f.Params {
+		declared := c.resolve(param.Type)
+
+		if param.Default != nil {
+			t := c.checkExpr(param.Default)
+			if !assignable(declared, t) {
+				c.errorf(param.Line, "Parameter '%s' of %s must be %v, got %v.", param.Name, f.Name, param.Type, t)
+			}
+		}
+
+		if param.Rest {
+			c.declare(&f.Params[i], param.Name, &staticType{Kind: "list"}, nil)
+			continue
+		}
+
+		c.declare(&f.Params[i], param.Name, declared, param.Type)
+	}
+
+	if f.ReturnType != nil {
+		c.resolve(f.ReturnType)
+	}
+
+	function := c.function
+	c.function = f
+
+	defer func() {
+		c.function = function
+	}()
+
+	// abstract methods and interface signatures have no body
+	if f.Body != nil {
+		c.checkStmt(f.Body)
+	}
+}
+
+func (c *Checker) checkClass(decl *ClassDeclStmt) {
+	t := c.declareClass(decl)
+
+	if decl.SuperClass != nil {
+		sc := c.checkExpr(decl.SuperClass)
+		if sc != nil && sc.Kind != "class" {
+			c.errorf(decl.SuperClass.Line, "%s must be of class type.", decl.SuperClass.Name)
+		}
+	}
+
+	for _, f := range decl.Fields {
+		c.resolve(f.Type)
+	}
+
+	for _, f := range decl.StaticFields {
+		if f.Expr != nil {
+			c.checkExpr(f.Expr)
+		}
+	}
+
+	c.beginScope()
+	defer c.endScope()
+
+	c.declare(decl, "this", t, nil)
+
+	for _, m := range decl.StaticMethods {
+		c.checkFunction(m)
+	}
+
+	c.declare(decl, "this", &staticType{Kind: "instance", Name: decl.Name, Class: t.Class}, nil)
+
+	for _, m := range slices.Concat(decl.Methods, decl.Getters, decl.Setters) {
+		c.checkFunction(m)
+	}
+}
+
+func (c *Checker) checkMatchCases(cases []*MatchCase) {
+	for _, mc := range cases {
+		c.beginScope()
+
+		for _, p := range mc.Patterns {
+			c.declarePattern(p)
+		}
+
+		if mc.Guard != nil {
+			c.checkExpr(mc.Guard)
+		}
+
+		if mc.Body != nil {
+			c.checkStmt(mc.Body)
+		} else {
+			c.checkExpr(mc.Expr)
+		}
+
+		c.endScope()
+	}
+}
+
+func (c *Checker) declarePattern(p Pattern) {
+	switch pt := p.(type) {
+	case *BindingPattern:
+		c.declare(pt, pt.Name, nil, nil)
+	case *ValuePattern:
+		c.checkExpr(pt.Expr)
+	case *ListPattern:
+		for _, e := range pt.Elements {
+			c.declarePattern(e)
+		}
+
+		if pt.Rest != nil {
+			c.declarePattern(pt.Rest)
+		}
+	case *ClassPattern:
+		c.checkExpr(pt.Class)
+
+		for _, a := range pt.Args {
+			c.declarePattern(a)
+		}
+
+		for _, f := range pt.Named {
+			c.declarePattern(f.Pattern)
+		}
+	}
+}
+
+// isKnown reports whether t is a known type which is not an instance, instances might
+// implement operators through hooks.
+func isKnown(t *staticType) bool {
+	return t != nil && t.Kind != "instance"
+}
+
+func (c *Checker) checkExpr(expr Expression) *staticType {
+	switch e := expr.(type) {
+	case *NilExpr:
+		return &staticType{Kind: "nil"}
+	case *LiteralExpr:
+		switch e.Literal.(type) {
+		case int64, float64, *big.Int, *big.Rat:
+			return &staticType{Kind: "number"}
+		}
+
+		return &staticType{Kind: typeName(e.Literal)}
+	case *GroupingExpr:
+		return c.checkExpr(e.Expr)
+	case *IdentifierExpr:
+		if v, ok := c.lookup(e.Name); ok {
+			return v.Type
+		}
+
+		// top level code runs before the declarations after it
+		if c.function == nil && c.topLevel[e.Name] {
+			if _, ok := NewInterpreter().env.Bindings[e.Name]; !ok {
+				c.errorf(e.Line, "Undefined variable '%s'.", e.Name)
+			}
+		}
+
+		return nil
+	case *AssignmentExpr:
+		t := c.checkExpr(e.Expr)
+		c.markAssignedName(e.Name)
+
+		if v, ok := c.lookup(e.Name); ok && v.Declared != nil {
+			if !assignable(c.resolve(v.Declared), t) {
+				c.errorf(e.Line, "Variable '%s' must be %v, got %v.", e.Name, v.Declared, t)
+			}
+		}
+
+		return t
+	case *UnaryExpr:
+		t := c.checkExpr(e.Expr)
+
+		if e.Unary == string(BANG) {
+			return &staticType{Kind: "bool"}
+		}
+
+		if !isKnown(t) {
+			return nil
+		}
+
+		if t.Kind != "number" {
+			c.errorf(e.Line, "Operand must be a number, got %v.", t)
+		}
+
+		return &staticType{Kind: "number"}
+	case *BinaryExpr:
+		return c.checkBinary(TokenType(e.Operator), c.checkExpr(e.LeftExpr), c.checkExpr(e.RightExpr), e.Line)
+	case *LogicalExpr:
+		left, right := c.checkExpr(e.LeftExpr), c.checkExpr(e.RightExpr)
+		if left != nil && right != nil && left.Kind == right.Kind && left.Name == right.Name {
+			return left
+		}
+
+		return nil
+	case *ConditionalExpr:
+		c.checkExpr(e.Cond)
+
+		then, els := c.checkExpr(e.Then), c.checkExpr(e.Else)
+		if then != nil && els != nil && then.Kind == els.Kind && then.Name == els.Name {
+			return then
+		}
+
+		return nil
+	case *UpdateExpr:
+		target := c.checkExpr(e.Target)
+
+		if id, ok := e.Target.(*IdentifierExpr); ok {
+			c.markAssignedName(id.Name)
+		}
+
+		if e.Expr == nil {
+			if isKnown(target) && target.Kind != "number" {
+				c.errorf(e.Line, "Operand must be a number, got %v.", target)
+			}
+
+			return nil
+		}
+
+		t := c.checkBinary(e.Operator, target, c.checkExpr(e.Expr), e.Line)
+
+		if id, ok := e.Target.(*IdentifierExpr); ok {
+			if v, ok := c.lookup(id.Name); ok && v.Declared != nil && !assignable(c.resolve(v.Declared), t) {
+				c.errorf(e.Line, "Variable '%s' must be %v, got %v.", id.Name, v.Declared, t)
+			}
+		}
+
+		return t
+	case *CallExpr:
+		return c.checkCall(e)
+	case *SpawnExpr:
+		c.checkCall(e.Call)
+		return &staticType{Kind: "task"}
+	case *AwaitExpr:
+		t := c.checkExpr(e.Expr)
+		if t != nil && t.Kind != "task" {
+			c.errorf(e.Line, "Can only await tasks, got %v.", t)
+		}
+
+		return nil
+	case *ObjectGetExpr:
+		return c.checkGet(e)
+	case *ObjectSetExpr:
+		obj, t := c.checkExpr(e.Object), c.checkExpr(e.Expr)
+
+		if obj != nil && obj.Kind == "instance" {
+			if declared, ok := obj.Class.field(e.Prop); ok && !assignable(c.resolve(declared), t) {
+				c.errorf(e.Line, "Field '%s' of %s must be %v, got %v.", e.Prop, obj.Name, declared, t)
+			}
+		}
+
+		return t
+	case *OptionalChainExpr:
+		t := c.checkExpr(e.Expr)
+		if t == nil {
+			return nil
+		}
+
+		optional := *t
+		optional.Nilable = true
+
+		return &optional
+	case *IndexExpr:
+		c.checkExpr(e.Object)
+		c.checkExpr(e.Index)
+	case *IndexSetExpr:
+		c.checkExpr(e.Object)
+		c.checkExpr(e.Index)
+
+		return c.checkExpr(e.Expr)
+	case *ListExpr:
+		for _, el := range e.Elements {
+			c.checkExpr(el)
+		}
+
+		return &staticType{Kind: "list"}
+	case *SpreadExpr:
+		c.checkExpr(e.Expr)
+	case *DestructureAssignExpr:
+		c.checkExpr(e.Target)
+		c.markAssigned(e.Target)
+
+		return c.checkExpr(e.Expr)
+	case *MatchExpr:
+		c.checkExpr(e.Subject)
+		c.checkMatchCases(e.Cases)
+	}
+
+	return nil
+}
+
+func (c *Checker) markAssigned(target Expression) {
+	switch t := target.(type) {
+	case *IdentifierExpr:
+		c.markAssignedName(t.Name)
+	case *ListExpr:
+		for _, e := range t.Elements {
+			c.markAssigned(e)
+		}
+	case *SpreadExpr:
+		c.markAssigned(t.Expr)
+	}
+}
+
+// checkBinary checks the operands of a binary operator and returns the type of its
+// result, following binaryOperation.
+func (c *Checker) checkBinary(operator TokenType, left, right *staticType, line int) *staticType {
+	if left != nil && left.Kind == "instance" {
+		// the class may define the operator
+		return nil
+	}
+
+	switch operator {
+	case SLASH, STAR, MINUS, PERCENT, LESS, LESS_EQUAL, GREATER, GREATER_EQUAL:
+		result := &staticType{Kind: "number"}
+		if operator != SLASH && operator != STAR && operator != MINUS && operator != PERCENT {
+			result = &staticType{Kind: "bool"}
+
+			if left != nil && left.Kind == "enum value" {
+				if right != nil && (right.Kind != "enum value" || right.Enum != left.Enum) {
+					c.errorf(line, "Operands must be values of the same enum, got %v and %v.", left, right)
+				}
+
+				return result
+			}
+		}
+
+		for _, t := range []*staticType{left, right} {
+			if t != nil && t.Kind != "number" {
+				c.errorf(line, "Operands must be numbers, got %v and %v.", typeOrAny(left), typeOrAny(right))
+				break
+			}
+		}
+
+		return result
+	case PLUS:
+		for _, t := range []*staticType{left, right} {
+			if t != nil && t.Kind != "number" && t.Kind != "string" {
+				c.errorf(line, "Operands must be two numbers or two strings, got %v and %v.", typeOrAny(left), typeOrAny(right))
+				return nil
+			}
+		}
+
+		if left == nil || right == nil {
+			if left != nil {
+				return left
+			}
+
+			return right
+		}
+
+		if left.Kind != right.Kind {
+			c.errorf(line, "Operands must be two numbers or two strings, got %v and %v.", left, right)
+			return nil
+		}
+
+		return &staticType{Kind: left.Kind}
+	case EQUAL_EQUAL, BANG_EQUAL:
+		return &staticType{Kind: "bool"}
+	case IS:
+		if right != nil && right.Kind != "class" && right.Kind != "trait" && right.Kind != "interface" {
+			c.errorf(line, "Right operand of 'is' must be a class, a trait or an interface, got %v.", right)
+		}
+
+		return &staticType{Kind: "bool"}
+	}
+
+	return nil
+}
+
+func typeOrAny(t *staticType) string {
+	if t == nil {
+		return "any"
+	}
+
+	return t.String()
+}
+
+func (c *Checker) checkGet(e *ObjectGetExpr) *staticType {
+	obj := c.checkExpr(e.Object)
+	if obj == nil || (obj.Nilable && e.Optional) {
+		return nil
+	}
+
+	switch obj.Kind {
+	case "instance":
+		if declared, ok := obj.Class.field(e.Prop); ok {
+			return c.resolve(declared)
+		}
+
+		if m, ok := obj.Class.method(e.Prop); ok {
+			return &staticType{Kind: "function", Fun: m}
+		}
+	case "enum":
+		if e.Prop == "values" {
+			return &staticType{Kind: "function"}
+		}
+
+		if !slices.Contains(obj.Enum.Values, e.Prop) {
+			c.errorf(e.Line, "Enum %s has no value called %s.", obj.Name, e.Prop)
+			return nil
+		}
+
+		return &staticType{Kind: "enum value", Name: obj.Name, Enum: obj.Enum}
+	case "enum value":
+		switch e.Prop {
+		case "name":
+			return &staticType{Kind: "string"}
+		case "ordinal":
+			return &staticType{Kind: "number"}
+		}
+
+		c.errorf(e.Line, "%v has no property called %s.", obj, e.Prop)
+	case "number", "string", "bool", "list", "range", "task", "function":
+		c.errorf(e.Line, "Only instances have properties, got %v.", obj)
+	case "nil":
+		if !e.Optional {
+			c.errorf(e.Line, "Only instances have properties, got nil.")
+		}
+	}
+
+	return nil
+}
+
+func (c *Checker) checkCall(e *CallExpr) *staticType {
+	callee := c.checkExpr(e.Callee)
+
+	args := make([]*staticType, 0, len(e.Args))
+	spread := false
+
+	for _, arg := range e.Args {
+		args = append(args, c.checkExpr(arg))
+
+		if _, ok := arg.(*SpreadExpr); ok {
+			spread = true
+		}
+	}
+
+	named := make(map[string]*staticType, len(e.Named))
+	for _, arg := range e.Named {
+		named[arg.Name] = c.checkExpr(arg.Expr)
+	}
+
+	if callee == nil {
+		return nil
+	}
+
+	var result *staticType
+
+	switch callee.Kind {
+	case "function":
+		if callee.Fun != nil && !callee.Fun.Generator {
+			result = c.resolve(callee.Fun.ReturnType)
+		}
+
+		if callee.Fun != nil && callee.Fun.Generator {
+			result = &staticType{Kind: "generator"}
+		}
+	case "class":
+		if callee.Class != nil {
+			result = &staticType{Kind: "instance", Name: callee.Name, Class: callee.Class}
+		}
+	default:
+		c.errorf(e.Line, "Can only call functions and classes, got %v.", callee)
+		return nil
+	}
+
+	f := callee.Fun
+	if f == nil || spread {
+		return result
+	}
+
+	name := f.Name
+	if callee.Kind == "class" {
+		name = callee.Name
+	}
+
+	if len(e.Named) == 0 {
+		fc := FunCaller{Params: f.Params}
+		if !acceptsArgs(&fc, len(args)) {
+			c.errorf(e.Line, "%s but got %d for %s.", expectedArgs(&fc), len(args), name)
+			return result
+		}
+	}
+
+	for i, param := range f.Params {
+		var (
+			arg   *staticType
+			given bool
+		)
+
+		switch {
+		case param.Rest:
+			continue
+		case i < len(args):
+			arg, given = args[i], true
+		default:
+			arg, given = named[param.Name]
+		}
+
+		if given && !assignable(c.resolve(param.Type), arg) {
+			c.errorf(e.Line, "Argument '%s' of %s must be %v, got %v.", param.Name, name, param.Type, arg)
+		}
+	}
+
+	return result
+}
+
+// CheckProgram parses and type checks a program, it returns the syntax errors if there
+// are any and the type errors otherwise.
+func CheckProgram(content []byte) []error {
+	scanner := NewScanner(content)
+
+	var tokens []*Token
+	for scanner.HasNext() {
+		token, err := scanner.NextToken()
+		if err != nil {
+			return []error{err}
+		}
+
+		tokens = append(tokens, token)
+	}
+
+	parser := NewParser(tokens)
+
+	var stmts []Statement
+	for stmt, err := parser.NextDeclaration(); !errors.Is(err, ErrNoMoreTokens); stmt, err = parser.NextDeclaration() {
+		if err != nil {
+			return []error{err}
+		}
+
+		stmts = append(stmts, stmt)
+	}
+
+	return NewChecker().Check(stmts)
+}
@@ -0,0 +1,199 @@
+package main
+
+import "testing"
+
+func TestCheckProgramReportsErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{
+			name:   "argument type",
+			source: `fun add(a: number, b: number): number { return a + b; } add("x", 1);`,
+			want:   "[line 1] Error: Argument 'a' of add must be number, got string.",
+		},
+		{
+			name:   "argument count",
+			source: `fun add(a: number, b: number): number { return a + b; } add(1);`,
+			want:   "[line 1] Error: Expected 2 arguments but got 1 for add.",
+		},
+		{
+			name:   "variable type",
+			source: `var s: string = 1;`,
+			want:   "[line 1] Error: Variable 's' must be string, got number.",
+		},
+		{
+			name:   "explicit nil initializer",
+			source: `var x: number = nil;`,
+			want:   "[line 1] Error: Variable 'x' must be number, got nil.",
+		},
+		{
+			name:   "call of a non-callable",
+			source: `var n = 1; n();`,
+			want:   "[line 1] Error: Can only call functions and classes, got number.",
+		},
+		{
+			name:   "binary operands",
+			source: `print "a" - 1;`,
+			want:   "[line 1] Error: Operands must be numbers, got string and number.",
+		},
+		{
+			name:   "unknown type",
+			source: `var x: Nope = 1;`,
+			want:   "[line 1] Error: Unknown type 'Nope'.",
+		},
+		{
+			name:   "return type",
+			source: "fun f(): string {\n  return 1;\n}",
+			want:   "[line 2] Error: f must return string, got number.",
+		},
+		{
+			name:   "constructor argument",
+			source: `class Point { init(x: number) { this.x = x; } } var p: Point = Point("a");`,
+			want:   "[line 1] Error: Argument 'x' of Point must be number, got string.",
+		},
+		{
+			name:   "call before the declaration",
+			source: "print f(1);\nfun f(a: number): number { return a; }",
+			want:   "[line 1] Error: Undefined variable 'f'.",
+		},
+		{
+			name:   "use of a class before its declaration",
+			source: "{ var p = Point(); }\nclass Point {}",
+			want:   "[line 1] Error: Undefined variable 'Point'.",
+		},
+		{
+			name: "assignment to a shadowing variable",
+			source: `
+var n = 1;
+fun f() { var n = 1; n = "s"; }
+print n + "s";
+`,
+			want: "[line 4] Error: Operands must be two numbers or two strings, got number and string.",
+		},
+		{
+			name: "assignment to a shadowing parameter",
+			source: `
+var n = 1;
+fun f(n) { n = "s"; }
+print n + "s";
+`,
+			want: "[line 4] Error: Operands must be two numbers or two strings, got number and string.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := CheckProgram([]byte(tt.source))
+			if len(errs) != 1 || errs[0].Error() != tt.want {
+				t.Fatalf("got %v, want [%s]", errs, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckProgramAcceptsValidPrograms(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+	}{
+		{
+			name: "annotated declarations",
+			source: `
+fun add(a: number, b: number): number { return a + b; }
+var s: string = "hi";
+var n: number? = nil;
+var later: number;
+later = add(1, 2);
+print add(later, 2);
+`,
+		},
+		{
+			name: "classes and enums",
+			source: `
+class Point {
+  x: number;
+  y: number;
+  init(x: number, y: number) { this.x = x; this.y = y; }
+  norm(): number { return this.x * this.x + this.y * this.y; }
+}
+enum Color { Red, Green }
+var p: Point = Point(1, 2);
+var c: Color = Color.Red;
+print p.norm();
+print c;
+`,
+		},
+		{
+			name: "default and named arguments",
+			source: `
+fun greet(name: string, greeting: string = "hello"): string { return greeting + " " + name; }
+print greet("bob");
+print greet("bob", greeting: "hi");
+`,
+		},
+		{
+			name: "untyped code",
+			source: `
+var w;
+print w;
+class A { static s; }
+print A.s;
+fun id(x) { return x; }
+print id(1) + id(2);
+print "a" + "b";
+`,
+		},
+		{
+			name: "function declared after the functions calling it",
+			source: `
+fun f(): number { return g(1); }
+fun g(a: number): number { return a; }
+print f();
+`,
+		},
+		{
+			name: "annotation naming a class declared later",
+			source: `
+fun make(): Point { return Point(); }
+class Point {}
+var p: Point = make();
+`,
+		},
+		{
+			name: "assignment by a function declared before the variable",
+			source: `
+fun f() { n = "s"; }
+var n = 1;
+f();
+print n + "s";
+`,
+		},
+		{
+			name: "assignment to a variable declared again",
+			source: `
+var n = 1;
+fun f() { n = "s"; }
+var n = 2;
+f();
+print n + "s";
+`,
+		},
+		{
+			name: "native used before a declaration of the same name",
+			source: `
+print clock();
+var clock = 1;
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if errs := CheckProgram([]byte(tt.source)); len(errs) != 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+		})
+	}
+}
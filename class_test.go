@@ -0,0 +1,68 @@
+package main
+
+import "testing"
+
+func TestAbstractMethods(t *testing.T) {
+	runProgramTests(t, []programTest{
+		{
+			name: "implemented by a subclass",
+			source: `
+class Shape { abstract area(); describe() { return "area " + str(this.area()); } }
+class Square < Shape { init(s) { this.s = s; } area() { return this.s * this.s; } }
+print Square(3).describe();
+`,
+			want: "area 9\n",
+		},
+		{
+			name: "implementation accepting default parameters",
+			source: `
+class Shape { abstract scale(k); }
+class Square < Shape { scale(k, by = 1) { return k * by; } }
+print Square().scale(2);
+`,
+			want: "2\n",
+		},
+		{
+			name: "unimplemented",
+			source: `
+class Shape { abstract area(); }
+Shape();
+`,
+			wantErr: "Cannot instantiate abstract class Shape, method area is not implemented.\n[line 3]",
+		},
+		{
+			name: "implemented by a subclass with another arity",
+			source: `
+class Shape { abstract area(); }
+class Square < Shape { area(s) { return s * s; } }
+`,
+			wantErr: "Class Square must implement abstract method area of Shape accepting 0 arguments, it expected 1 arguments.\n[line 3]",
+		},
+		{
+			name: "abstract method of a grandparent",
+			source: `
+class Shape { abstract area(); }
+class Polygon < Shape { abstract sides(); }
+class Square < Polygon { area(s) {} }
+`,
+			wantErr: "Class Square must implement abstract method area of Polygon accepting 0 arguments, it expected 1 arguments.\n[line 4]",
+		},
+		{
+			name: "implemented for a trait with another arity",
+			source: `
+trait Named { abstract name(); hello() { return "hi " + this.name(); } }
+class Person with Named { name(first, last) { return first + last; } }
+`,
+			wantErr: "Class Person must implement abstract method name of Named accepting 0 arguments, it expected 2 arguments.\n[line 3]",
+		},
+		{
+			name: "implemented for a trait by the superclass with another arity",
+			source: `
+trait Named { abstract name(); }
+class Base { name(prefix) { return prefix; } }
+class Person < Base with Named {}
+`,
+			wantErr: "Class Person must implement abstract method name of Named accepting 0 arguments, it expected 1 arguments.\n[line 4]",
+		},
+	})
+}
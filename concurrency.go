@@ -0,0 +1,548 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Tasks started by spawn run on goroutines of their own, but only the task holding
+// interpreterLock runs Lox code. A task releases the lock while it waits on a channel,
+// another task, a generator or sleep(), which lets the others run in the meantime. This
+// keeps environments, lists and instances shared between tasks free of data races
+// without locking each of them, while tasks spending their time waiting still run in
+// parallel.
+var interpreterLock sync.Mutex
+
+// The fields of scheduler are only accessed while holding the interpreter lock.
+var scheduler = struct {
+	// live counts the tasks which have not finished, the main script included, and
+	// blocked those waiting on a channel or another task. When all live tasks are
+	// blocked none of them can ever be woken up.
+	live    int
+	blocked int
+
+	// waiting holds the waiters of the blocked tasks, deadlocked tasks are woken up
+	// from there.
+	waiting map[*waiter]struct{}
+
+	// tasks are the tasks spawned so far, the main script waits for them to finish.
+	tasks []*Task
+}{live: 1, waiting: map[*waiter]struct{}{}}
+
+var errDeadlock = errors.New("Deadlock: all tasks are blocked.")
+
+// blocking releases the interpreter lock while wait runs. The task is not counted as
+// blocked, wait must return on its own.
+func blocking(wait func()) {
+	interpreterLock.Unlock()
+	defer interpreterLock.Lock()
+
+	wait()
+}
+
+// waiter is a task blocked until another task completes one of the operations it
+// waits for, like a send, a receive, the end of a task or a generator another task is
+// running.
+type waiter struct {
+	wake chan struct{}
+	done bool
+
+	// index is the operation which completed, the case of a select statement, with
+	// the value received and whether the channel was still open.
+	index int
+	val   interface{}
+	ok    bool
+	err   error
+}
+
+func newWaiter() *waiter {
+	return &waiter{wake: make(chan struct{}, 1)}
+}
+
+// block waits until the waiter is completed, it fails instead when all the other
+// tasks are blocked as well.
+func (w *waiter) block() error {
+	scheduler.blocked++
+
+	if scheduler.blocked == scheduler.live {
+		scheduler.blocked--
+		w.done = true
+
+		return errDeadlock
+	}
+
+	scheduler.waiting[w] = struct{}{}
+
+	blocking(func() {
+		<-w.wake
+	})
+
+	return w.err
+}
+
+// complete wakes the task blocked on the waiter up.
+func (w *waiter) complete(index int, val interface{}, ok bool, err error) {
+	w.done = true
+	w.index, w.val, w.ok, w.err = index, val, ok, err
+
+	delete(scheduler.waiting, w)
+	scheduler.blocked--
+	w.wake <- struct{}{}
+}
+
+// Task is the handle spawn returns, awaiting it returns the result of the call.
+type Task struct {
+	done    bool
+	result  interface{}
+	err     error
+	waiters []*waiter
+
+	// awaited is set once the result of the task has been waited for, errors of tasks
+	// nobody awaits are reported when the main script ends.
+	awaited bool
+}
+
+func (t *Task) wait() (interface{}, error) {
+	t.awaited = true
+
+	if !t.done {
+		w := newWaiter()
+		t.waiters = append(t.waiters, w)
+
+		err := w.block()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return t.result, t.err
+}
+
+// finish records the result of the task and wakes up the tasks awaiting it. When the
+// tasks still running are all blocked, they are deadlocked and woken up with an error.
+func (t *Task) finish(result interface{}, err error) {
+	t.done, t.result, t.err = true, result, err
+
+	for _, w := range t.waiters {
+		if !w.done {
+			w.complete(0, nil, true, nil)
+		}
+	}
+
+	t.waiters = nil
+
+	scheduler.live--
+
+	if scheduler.live > 0 && scheduler.blocked == scheduler.live {
+		for w := range scheduler.waiting {
+			w.complete(0, nil, false, errDeadlock)
+		}
+	}
+}
+
+func (t *Task) String() string {
+	return "<task>"
+}
+
+// waitForTasks is called when the main script ends, it waits for the spawned tasks and
+// returns the error of the first one which failed without being awaited.
+func waitForTasks() error {
+	for i := 0; i < len(scheduler.tasks); i++ {
+		t := scheduler.tasks[i]
+
+		awaited := t.awaited
+
+		// a task which is not done after waiting is deadlocked
+		_, err := t.wait()
+		if err != nil && (!awaited || !t.done) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SpawnExpr evaluates the callee and the arguments of a call and makes the call on a
+// new task.
+type SpawnExpr struct {
+	Call *CallExpr
+	Line int
+}
+
+func (se *SpawnExpr) Eval(env *Environment) (interface{}, error) {
+	call, err := se.Call.prepare(env)
+	if err != nil {
+		return nil, err
+	}
+
+	t := Task{}
+
+	scheduler.live++
+	scheduler.tasks = append(scheduler.tasks, &t)
+
+	go func() {
+		interpreterLock.Lock()
+		defer interpreterLock.Unlock()
+
+		t.finish(call())
+	}()
+
+	return &t, nil
+}
+
+func (se *SpawnExpr) String() string {
+	return fmt.Sprintf("(spawn %v)", se.Call.Callee)
+}
+
+// AwaitExpr waits for a task to finish, it evaluates to the value the task returned
+// and fails with the error the task failed with.
+type AwaitExpr struct {
+	Expr Expression
+	Line int
+}
+
+func (ae *AwaitExpr) Eval(env *Environment) (interface{}, error) {
+	val, err := ae.Expr.Eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	t, ok := val.(*Task)
+	if !ok {
+		return nil, fmt.Errorf("Can only await tasks.\n[line %d]", ae.Line)
+	}
+
+	ret, err := t.wait()
+	if err != nil && !t.done {
+		// the awaiting task itself is deadlocked
+		return nil, fmt.Errorf("%w\n[line %d]", err, ae.Line)
+	}
+
+	return ret, err
+}
+
+func (ae *AwaitExpr) String() string {
+	return fmt.Sprintf("(await %v)", ae.Expr)
+}
+
+// channelWaiter is a task blocked on a channel, for the case index of a select
+// statement. Senders wait with the value they send.
+type channelWaiter struct {
+	*waiter
+	index int
+	val   interface{}
+}
+
+// Channel passes values between tasks. Receiving from a closed channel returns the
+// values still buffered and then nil. Channels keep track of the tasks blocked on them
+// themselves, so the scheduler always knows whether a task can still be woken up.
+type Channel struct {
+	buffer   []interface{}
+	capacity int
+	closed   bool
+
+	receivers []channelWaiter
+	senders   []channelWaiter
+}
+
+var errSendOnClosed = errors.New("Cannot send on a closed channel.")
+
+// popWaiter removes and returns the first waiter of queue still waiting, waiters of
+// select statements which completed another case are dropped.
+func popWaiter(queue *[]channelWaiter) (channelWaiter, bool) {
+	for len(*queue) > 0 {
+		w := (*queue)[0]
+		*queue = (*queue)[1:]
+
+		if !w.done {
+			return w, true
+		}
+	}
+
+	return channelWaiter{}, false
+}
+
+// hasWaiter reports whether a task waits in queue, dropping the stale waiters at its
+// front.
+func hasWaiter(queue *[]channelWaiter) bool {
+	for len(*queue) > 0 && (*queue)[0].done {
+		*queue = (*queue)[1:]
+	}
+
+	return len(*queue) > 0
+}
+
+// canRecv reports whether receiving from the channel would not block.
+func (c *Channel) canRecv() bool {
+	return len(c.buffer) > 0 || c.closed || hasWaiter(&c.senders)
+}
+
+// canSend reports whether sending on the channel would not block, sending on a closed
+// channel fails right away.
+func (c *Channel) canSend() bool {
+	return c.closed || len(c.buffer) < c.capacity || hasWaiter(&c.receivers)
+}
+
+// tryRecv receives a value from the channel, canRecv must be true.
+func (c *Channel) tryRecv() (interface{}, bool) {
+	if len(c.buffer) > 0 {
+		val := c.buffer[0]
+		c.buffer = c.buffer[1:]
+
+		// a blocked sender takes the freed slot
+		if s, ok := popWaiter(&c.senders); ok {
+			c.buffer = append(c.buffer, s.val)
+			s.complete(s.index, nil, true, nil)
+		}
+
+		return val, true
+	}
+
+	if s, ok := popWaiter(&c.senders); ok {
+		s.complete(s.index, nil, true, nil)
+		return s.val, true
+	}
+
+	return nil, false
+}
+
+// trySend sends a value on the channel, canSend must be true.
+func (c *Channel) trySend(val interface{}) error {
+	if c.closed {
+		return errSendOnClosed
+	}
+
+	if r, ok := popWaiter(&c.receivers); ok {
+		r.complete(r.index, val, true, nil)
+		return nil
+	}
+
+	c.buffer = append(c.buffer, val)
+
+	return nil
+}
+
+func (c *Channel) send(val interface{}) error {
+	if c.canSend() {
+		return c.trySend(val)
+	}
+
+	w := newWaiter()
+	c.senders = append(c.senders, channelWaiter{waiter: w, val: val})
+
+	return w.block()
+}
+
+func (c *Channel) recv() (interface{}, bool, error) {
+	if c.canRecv() {
+		val, ok := c.tryRecv()
+		return val, ok, nil
+	}
+
+	w := newWaiter()
+	c.receivers = append(c.receivers, channelWaiter{waiter: w})
+
+	err := w.block()
+
+	return w.val, w.ok, err
+}
+
+func (c *Channel) close() error {
+	if c.closed {
+		return errors.New("Channel is already closed.")
+	}
+
+	c.closed = true
+
+	for r, ok := popWaiter(&c.receivers); ok; r, ok = popWaiter(&c.receivers) {
+		r.complete(r.index, nil, false, nil)
+	}
+
+	for s, ok := popWaiter(&c.senders); ok; s, ok = popWaiter(&c.senders) {
+		s.complete(s.index, nil, false, errSendOnClosed)
+	}
+
+	return nil
+}
+
+// method returns the method called name of the channel, bound to it.
+func (c *Channel) method(name string) (Caller, bool) {
+	switch name {
+	case "send":
+		return &BuiltinMethod{MinArity: 1, MaxArity: 1, call: func(args ...interface{}) (interface{}, error) {
+			return nil, c.send(args[0])
+		}}, true
+	case "recv":
+		return &BuiltinMethod{call: func(_ ...interface{}) (interface{}, error) {
+			val, _, err := c.recv()
+			return val, err
+		}}, true
+	case "close":
+		return &BuiltinMethod{call: func(_ ...interface{}) (interface{}, error) {
+			return nil, c.close()
+		}}, true
+	}
+
+	return nil, false
+}
+
+func (c *Channel) String() string {
+	return fmt.Sprintf("<channel %d>", c.capacity)
+}
+
+// NativeChannel creates a channel, unbuffered unless given a capacity.
+type NativeChannel struct{}
+
+func (nc *NativeChannel) Call(args ...interface{}) (interface{}, error) {
+	capacity := int64(0)
+
+	if len(args) > 0 {
+		c, ok := args[0].(int64)
+		if !ok || c < 0 {
+			return nil, errors.New("Channel() expects a capacity which is a non-negative integer.")
+		}
+
+		capacity = c
+	}
+
+	return &Channel{capacity: int(capacity)}, nil
+}
+
+func (nc *NativeChannel) Arity() (int, int) { return 0, 1 }
+
+func (nc *NativeChannel) String() string {
+	return "<native fn>"
+}
+
+// NativeSleep pauses the task for a number of milliseconds, letting other tasks run.
+type NativeSleep struct{}
+
+func (ns *NativeSleep) Call(args ...interface{}) (interface{}, error) {
+	if !isNumber(args[0]) || toFloat(args[0]) < 0 {
+		return nil, errors.New("sleep() expects a non-negative number of milliseconds.")
+	}
+
+	blocking(func() {
+		time.Sleep(time.Duration(toFloat(args[0]) * float64(time.Millisecond)))
+	})
+
+	return nil, nil
+}
+
+func (ns *NativeSleep) Arity() (int, int) { return 1, 1 }
+
+func (ns *NativeSleep) String() string {
+	return "<native fn>"
+}
+
+// SelectCase sends Value to or, when it is nil, receives from Channel. A received
+// value is bound to Name when it is set.
+type SelectCase struct {
+	Channel Expression
+	Value   Expression
+	Name    string
+	Body    Statement
+	Line    int
+}
+
+// SelectStmt waits until one of its cases can send or receive and runs that case, or
+// runs Default right away when none can and there is one. When several cases can, one
+// of them is picked at random.
+type SelectStmt struct {
+	Cases   []*SelectCase
+	Default Statement
+	Line    int
+}
+
+func (ss *SelectStmt) Execute(env *Environment) (interface{}, error) {
+	chans := make([]*Channel, 0, len(ss.Cases))
+	values := make([]interface{}, 0, len(ss.Cases))
+
+	for _, c := range ss.Cases {
+		val, err := c.Channel.Eval(env)
+		if err != nil {
+			return nil, err
+		}
+
+		ch, ok := val.(*Channel)
+		if !ok {
+			return nil, fmt.Errorf("Can only select on channels.\n[line %d]", c.Line)
+		}
+
+		var sent interface{}
+
+		if c.Value != nil {
+			if ch.closed {
+				return nil, fmt.Errorf("%w\n[line %d]", errSendOnClosed, c.Line)
+			}
+
+			sent, err = c.Value.Eval(env)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		chans = append(chans, ch)
+		values = append(values, sent)
+	}
+
+	var ready []int
+
+	for i, c := range ss.Cases {
+		if (c.Value == nil && chans[i].canRecv()) || (c.Value != nil && chans[i].canSend()) {
+			ready = append(ready, i)
+		}
+	}
+
+	var (
+		chosen   int
+		received interface{}
+	)
+
+	switch {
+	case len(ready) > 0:
+		chosen = ready[rand.Intn(len(ready))]
+
+		if ss.Cases[chosen].Value == nil {
+			received, _ = chans[chosen].tryRecv()
+		} else if err := chans[chosen].trySend(values[chosen]); err != nil {
+			return nil, fmt.Errorf("%w\n[line %d]", err, ss.Cases[chosen].Line)
+		}
+	case ss.Default != nil:
+		return ss.Default.Execute(ExpandEnv(env))
+	default:
+		w := newWaiter()
+
+		for i, c := range ss.Cases {
+			if c.Value == nil {
+				chans[i].receivers = append(chans[i].receivers, channelWaiter{waiter: w, index: i})
+			} else {
+				chans[i].senders = append(chans[i].senders, channelWaiter{waiter: w, index: i, val: values[i]})
+			}
+		}
+
+		err := w.block()
+		if err != nil {
+			line := ss.Line
+			if !errors.Is(err, errDeadlock) {
+				line = ss.Cases[w.index].Line
+			}
+
+			return nil, fmt.Errorf("%w\n[line %d]", err, line)
+		}
+
+		chosen, received = w.index, w.val
+	}
+
+	c := ss.Cases[chosen]
+	caseEnv := ExpandEnv(env)
+
+	if c.Name != "" {
+		caseEnv.SetBinding(c.Name, received)
+	}
+
+	return c.Body.Execute(caseEnv)
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConcurrency(t *testing.T) {
+	runProgramTests(t, []programTest{
+		{
+			name: "await returns the result",
+			source: `
+fun add(a, b) { return a + b; }
+var t = spawn add(1, 2);
+print await t;
+`,
+			want: "3\n",
+		},
+		{
+			name: "await fails with the error of the task",
+			source: `
+fun fail() { return 1 / nil; }
+var t = spawn fail();
+await t;
+`,
+			wantErr: "Operands must be numbers.\n[line 2]",
+		},
+		{
+			name: "unawaited tasks finish before the program ends",
+			source: `
+fun hello() { print "hello"; }
+spawn hello();
+`,
+			want: "hello\n",
+		},
+		{
+			name: "errors of unawaited tasks are reported",
+			source: `
+fun fail() { return 1 / nil; }
+spawn fail();
+print "before";
+`,
+			want:    "before\n",
+			wantErr: "Operands must be numbers.\n[line 2]",
+		},
+		{
+			name: "channel between tasks",
+			source: `
+fun produce(ch, n) { for (var i in range(n)) ch.send(i); ch.close(); }
+var ch = Channel();
+spawn produce(ch, 5);
+var sum = 0;
+for (var v in ch) sum += v;
+print sum;
+`,
+			want: "10\n",
+		},
+		{
+			name: "buffered channel",
+			source: `
+var ch = Channel(2);
+ch.send(1);
+ch.send(2);
+ch.close();
+print ch.recv();
+print ch.recv();
+print ch.recv();
+`,
+			want: "1\n2\nnil\n",
+		},
+		{
+			name: "send on a closed channel",
+			source: `
+var ch = Channel(1);
+ch.close();
+ch.send(1);
+`,
+			wantErr: "Cannot send on a closed channel.\n[line 4]",
+		},
+		{
+			name: "select picks the ready case",
+			source: `
+var a = Channel(1);
+var b = Channel(1);
+b.send("b");
+select {
+  case var v = a.recv() => print "a";
+  case var v = b.recv() => print v;
+}
+`,
+			want: "b\n",
+		},
+		{
+			name: "select runs the default case",
+			source: `
+var a = Channel();
+select {
+  case var v = a.recv() => print v;
+  case _ => print "default";
+}
+`,
+			want: "default\n",
+		},
+		{
+			name: "select waits for a case",
+			source: `
+var a = Channel();
+fun send() { sleep(1); a.send(1); }
+spawn send();
+select {
+  case var v = a.recv() => print v;
+}
+`,
+			want: "1\n",
+		},
+		{
+			name: "receive deadlock",
+			source: `
+var c = Channel();
+c.recv();
+`,
+			wantErr: "Deadlock: all tasks are blocked.\n[line 3]",
+		},
+		{
+			name: "select deadlock",
+			source: `
+var a = Channel();
+select {
+  case var v = a.recv() => print v;
+}
+`,
+			wantErr: "Deadlock: all tasks are blocked.\n[line 3]",
+		},
+		{
+			name: "await deadlock",
+			source: `
+var c = Channel();
+fun wait() { return c.recv(); }
+await spawn wait();
+`,
+			wantErr: "Deadlock: all tasks are blocked.\n[line 3]",
+		},
+		{
+			name: "task blocked when the program ends",
+			source: `
+var c = Channel();
+fun wait() { c.recv(); }
+spawn wait();
+`,
+			wantErr: "Deadlock: all tasks are blocked.\n[line 3]",
+		},
+		{
+			name: "generator shared between tasks",
+			source: `
+var c = Channel();
+fun* gen() {
+  while (true) {
+    var v = c.recv();
+    if (v == nil) return;
+    sleep(1);
+    yield v * 10;
+  }
+}
+var g = gen();
+fun take() {
+  var total = 0;
+  for (var x in g) total = total + x;
+  return total;
+}
+var a = spawn take();
+var b = spawn take();
+for (var i = 1; i <= 4; i = i + 1) c.send(i);
+c.close();
+print await a + await b;
+`,
+			want: "100\n",
+		},
+	})
+}
+
+func TestSharedGeneratorDeadlock(t *testing.T) {
+	// whether the generator or the task waiting for it blocks last, the program ends
+	// with a deadlock instead of hanging
+	_, err := runProgram(t, `
+var c = Channel();
+fun* gen() { yield c.recv(); }
+var g = gen();
+fun take() { return g.next(); }
+spawn take();
+g.next();
+`)
+	if err == nil || !strings.HasPrefix(err.Error(), errDeadlock.Error()) {
+		t.Fatalf("got error %v, want a deadlock", err)
+	}
+}
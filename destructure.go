@@ -0,0 +1,185 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Destructuring unpacks a value into several variables at once, "var [a, ...rest] = l;"
+// takes the elements of a list and "var {x, y} = p;" the fields of an instance. List
+// patterns nest, and assignments like "[a, b] = [b, a];" also accept properties and
+// indexed elements as targets. The value is always fully evaluated before anything is
+// assigned.
+
+// ObjectPattern is the "{x, y}" pattern of a destructuring declaration.
+type ObjectPattern struct {
+	Names []string
+	Line  int
+}
+
+func (op *ObjectPattern) Eval(_ *Environment) (interface{}, error) {
+	return nil, fmt.Errorf("Object patterns are only allowed in declarations.\n[line %d]", op.Line)
+}
+
+func (op *ObjectPattern) String() string {
+	return fmt.Sprintf("{%s}", strings.Join(op.Names, ", "))
+}
+
+type DestructureDeclStmt struct {
+	Target Expression
+	Expr   Expression
+	Const  bool
+	Line   int
+}
+
+func (d *DestructureDeclStmt) Execute(env *Environment) (interface{}, error) {
+	val, err := d.Expr.Eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	err = destructure(env, d.Target, val, d.Line, func(target Expression, val interface{}) error {
+		name := target.(*IdentifierExpr).Name
+
+		if d.Const {
+			env.SetConstant(name, val)
+		} else {
+			env.SetBinding(name, val)
+		}
+
+		return nil
+	})
+
+	return nil, err
+}
+
+type DestructureAssignExpr struct {
+	Target *ListExpr
+	Expr   Expression
+	Line   int
+}
+
+func (d *DestructureAssignExpr) Eval(env *Environment) (interface{}, error) {
+	val, err := d.Expr.Eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	err = destructure(env, d.Target, val, d.Line, func(target Expression, val interface{}) error {
+		switch t := target.(type) {
+		case *IdentifierExpr:
+			varEnv, ok := env.Lookup(t.Name)
+			if !ok {
+				return fmt.Errorf("Undefined variable '%s'.\n[line %d]", t.Name, t.Line)
+			}
+
+			if varEnv.IsConstant(t.Name) {
+				return fmt.Errorf("Cannot assign to constant '%s'.\n[line %d]", t.Name, t.Line)
+			}
+
+			varEnv.SetBinding(t.Name, val)
+
+			return nil
+		case *ObjectGetExpr:
+			obj, err := t.Object.Eval(env)
+			if err != nil {
+				return err
+			}
+
+			return setProperty(env, obj, t.Prop, val, t.Line)
+		case *IndexExpr:
+			obj, err := t.Object.Eval(env)
+			if err != nil {
+				return err
+			}
+
+			idx, err := t.Index.Eval(env)
+			if err != nil {
+				return err
+			}
+
+			return setIndex(obj, idx, val, t.Line)
+		}
+
+		// unreachable, the parser only accepts the targets above
+		return fmt.Errorf("Invalid assignment target.\n[line %d]", d.Line)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return val, nil
+}
+
+func (d *DestructureAssignExpr) String() string {
+	return fmt.Sprintf("(= %v %v)", d.Target, d.Expr)
+}
+
+// destructure checks val has the shape of target, a list or an object pattern, and
+// calls assign with every leaf target and the part of val it receives.
+func destructure(env *Environment, target Expression, val interface{}, line int, assign func(Expression, interface{}) error) error {
+	switch t := target.(type) {
+	case *ListExpr:
+		l, ok := val.(*List)
+		if !ok {
+			return fmt.Errorf("Cannot destructure %s as a list.\n[line %d]", typeName(val), line)
+		}
+
+		elements := t.Elements
+
+		var rest *SpreadExpr
+		if len(elements) > 0 {
+			rest, ok = elements[len(elements)-1].(*SpreadExpr)
+			if ok {
+				elements = elements[:len(elements)-1]
+			}
+		}
+
+		switch {
+		case rest == nil && len(l.Elements) != len(elements):
+			return fmt.Errorf("Expected a list of %s but got %d.\n[line %d]", plural(len(elements), "element"), len(l.Elements), line)
+		case len(l.Elements) < len(elements):
+			return fmt.Errorf("Expected a list of at least %s but got %d.\n[line %d]", plural(len(elements), "element"), len(l.Elements), line)
+		}
+
+		for i, e := range elements {
+			err := destructure(env, e, l.Elements[i], line, assign)
+			if err != nil {
+				return err
+			}
+		}
+
+		if rest == nil {
+			return nil
+		}
+
+		return destructure(env, rest.Expr, &List{Elements: append([]interface{}{}, l.Elements[len(elements):]...)}, line, assign)
+	case *ObjectPattern:
+		ci, ok := val.(*ClassInstance)
+		if !ok {
+			return fmt.Errorf("Cannot destructure %s as an object.\n[line %d]", typeName(val), line)
+		}
+
+		for _, name := range t.Names {
+			_, isField := ci.Properties[name]
+			_, hasGetter := ci.Class.findGetter(name)
+			if !isField && !hasGetter {
+				return fmt.Errorf("Object %s has no property called %s\n[line %d]", ci.Class.Name, name, line)
+			}
+
+			fieldVal, err := getProperty(env, ci, name, line)
+			if err != nil {
+				return err
+			}
+
+			err = assign(&IdentifierExpr{Name: name, Line: line}, fieldVal)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	return assign(target, val)
+}
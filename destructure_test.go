@@ -0,0 +1,88 @@
+package main
+
+import "testing"
+
+func TestDestructure(t *testing.T) {
+	runProgramTests(t, []programTest{
+		{
+			name:   "list declaration",
+			source: "var [a, b] = [1, 2];\nprint a + b;",
+			want:   "3\n",
+		},
+		{
+			name:   "rest element",
+			source: "const [a, ...rest] = [1, 2, 3];\nprint rest;",
+			want:   "[2, 3]\n",
+		},
+		{
+			name:   "empty rest",
+			source: "var [a, ...rest] = [1];\nprint rest;",
+			want:   "[]\n",
+		},
+		{
+			name:   "nested lists",
+			source: "var [a, [b, c]] = [1, [2, 3]];\nprint a + b + c;",
+			want:   "6\n",
+		},
+		{
+			name:   "object declaration",
+			source: "class P { init(x, y) { this.x = x; this.y = y; } }\nvar {x, y} = P(1, 2);\nprint x + y;",
+			want:   "3\n",
+		},
+		{
+			name:   "swap",
+			source: "var a = 1;\nvar b = 2;\n[a, b] = [b, a];\nprint [a, b];",
+			want:   "[2, 1]\n",
+		},
+		{
+			name:   "assignment to properties and elements",
+			source: "class P {}\nvar p = P();\nvar l = [0, 0];\n[l[1], p.x] = [5, 6];\nprint l;\nprint p.x;",
+			want:   "[0, 5]\n6\n",
+		},
+		{
+			name:    "too few elements",
+			source:  "var [a, b] = [1];",
+			wantErr: "Expected a list of 2 elements but got 1.\n[line 1]",
+		},
+		{
+			name:    "too many elements",
+			source:  "var [a] = [1, 2];",
+			wantErr: "Expected a list of 1 element but got 2.\n[line 1]",
+		},
+		{
+			name:    "too few elements for a rest",
+			source:  "var [a, b, ...rest] = [1];",
+			wantErr: "Expected a list of at least 2 elements but got 1.\n[line 1]",
+		},
+		{
+			name:    "too few elements for a rest in an assignment",
+			source:  "var a;\nvar rest;\n[a, ...rest] = [];",
+			wantErr: "Expected a list of at least 1 element but got 0.\n[line 3]",
+		},
+		{
+			name:    "nested list of the wrong shape",
+			source:  "var [a, [b]] = [1, 2];",
+			wantErr: "Cannot destructure number as a list.\n[line 1]",
+		},
+		{
+			name:    "not an instance",
+			source:  "var {x} = 1;",
+			wantErr: "Cannot destructure number as an object.\n[line 1]",
+		},
+		{
+			name:    "missing property",
+			source:  "class A {}\nvar {x} = A();",
+			wantErr: "Object A has no property called x\n[line 2]",
+		},
+		{
+			name:    "assignment to an undefined variable",
+			source:  "[a] = [1];",
+			wantErr: "Undefined variable 'a'.\n[line 1]",
+		},
+		{
+			name:    "assignment to a constant",
+			source:  "const [a] = [1];\n[a] = [2];",
+			wantErr: "[line 2] Error at 'a': Cannot assign to constant 'a'.",
+		},
+	})
+}
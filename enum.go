@@ -0,0 +1,77 @@
+package main
+
+import "fmt"
+
+// Enums are namespaces of named values declared with "enum Color { Red, Green }".
+// Every value is distinct and only equal to itself, values of the same enum compare by
+// the order they are declared in.
+
+type Enum struct {
+	Name   string
+	Values []*EnumValue
+}
+
+// property returns the values() method of the enum, or one of its values by name. The
+// parser rejects values called "values".
+func (e *Enum) property(name string) (interface{}, bool) {
+	if name == "values" {
+		return &BuiltinMethod{call: func(_ ...interface{}) (interface{}, error) {
+			values := make([]interface{}, 0, len(e.Values))
+			for _, v := range e.Values {
+				values = append(values, v)
+			}
+
+			return &List{Elements: values}, nil
+		}}, true
+	}
+
+	for _, v := range e.Values {
+		if v.Name == name {
+			return v, true
+		}
+	}
+
+	return nil, false
+}
+
+func (e *Enum) String() string {
+	return fmt.Sprintf("<enum %s>", e.Name)
+}
+
+type EnumValue struct {
+	Enum    *Enum
+	Name    string
+	Ordinal int64
+}
+
+func (ev *EnumValue) property(name string) (interface{}, bool) {
+	switch name {
+	case "name":
+		return ev.Name, true
+	case "ordinal":
+		return ev.Ordinal, true
+	}
+
+	return nil, false
+}
+
+func (ev *EnumValue) String() string {
+	return ev.Enum.Name + "." + ev.Name
+}
+
+type EnumDeclStmt struct {
+	Name   string
+	Values []string
+}
+
+func (e *EnumDeclStmt) Execute(env *Environment) (interface{}, error) {
+	enum := Enum{Name: e.Name}
+
+	for i, name := range e.Values {
+		enum.Values = append(enum.Values, &EnumValue{Enum: &enum, Name: name, Ordinal: int64(i)})
+	}
+
+	env.SetBinding(e.Name, &enum)
+
+	return nil, nil
+}
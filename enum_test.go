@@ -0,0 +1,38 @@
+package main
+
+import "testing"
+
+func TestEnums(t *testing.T) {
+	color := "enum Color { Red, Green, }\n"
+
+	runProgramTests(t, []programTest{
+		{name: "value", source: color + "print Color.Red;", want: "Color.Red\n"},
+		{name: "values()", source: color + "print Color.values();", want: "[Color.Red, Color.Green]\n"},
+		{name: "no values", source: "enum E {}\nprint E.values();", want: "[]\n"},
+		{name: "name and ordinal", source: color + "print Color.Green.name;\nprint Color.Green.ordinal;", want: "Green\n1\n"},
+		{name: "equality", source: color + "print Color.Red == Color.Red;\nprint Color.Red == Color.Green;", want: "true\nfalse\n"},
+		{name: "order", source: color + "print Color.Red < Color.Green;\nprint Color.Green <= Color.Red;", want: "true\nfalse\n"},
+		{name: "values of different enums", source: "enum E { A }\nenum F { A }\nprint E.A == F.A;", want: "false\n"},
+		{name: "printing an enum", source: color + "print Color;", want: "<enum Color>\n"},
+		{
+			name:    "comparison with another type",
+			source:  color + "print Color.Red < 1;",
+			wantErr: "Operands must be values of the same enum.\n[line 2]",
+		},
+		{
+			name:    "unknown value",
+			source:  color + "print Color.Blue;",
+			wantErr: "Enum Color has no value called Blue\n[line 2]",
+		},
+		{
+			name:    "duplicate value",
+			source:  "enum E { A, A }",
+			wantErr: "[line 1] Error at 'A': Enum E already has a value called A.",
+		},
+		{
+			name:    "value called values",
+			source:  "enum E { A, values }",
+			wantErr: "[line 1] Error at 'values': Enum values cannot be called values.",
+		},
+	})
+}
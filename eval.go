@@ -1,8 +1,10 @@
 package main
 
 import (
+	"errors"
 	"fmt"
-	"reflect"
+	"slices"
+	"strings"
 	"time"
 )
 
@@ -26,10 +28,8 @@ func (le *LiteralExpr) Eval(_ *Environment) (interface{}, error) {
 }
 
 func (le *LiteralExpr) String() string {
-	if v, ok := le.Literal.(float64); ok {
-		if v == float64(int64(v)) {
-			return fmt.Sprintf("%.1f", v)
-		}
+	if isNumber(le.Literal) {
+		return formatNumberLiteral(le.Literal)
 	}
 
 	return fmt.Sprintf("%v", le.Literal)
@@ -49,12 +49,22 @@ func (ue *UnaryExpr) Eval(env *Environment) (interface{}, error) {
 
 	switch TokenType(ue.Unary) {
 	case MINUS:
-		v, ok := val.(float64)
-		if !ok {
+		if ci, ok := val.(*ClassInstance); ok {
+			ret, found, err := callHook(ci, "__neg")
+			if found || err != nil {
+				return ret, err
+			}
+		}
+
+		if isBigNumber(val) {
+			return negateBigNumber(val), nil
+		}
+
+		if !isNumber(val) {
 			return nil, fmt.Errorf("Operand must be a number.\n[line %d]", ue.Line)
 		}
 
-		return -v, nil
+		return negateNumber(val), nil
 	case BANG:
 		return !isTrue(val), nil
 	}
@@ -85,55 +95,83 @@ func (be *BinaryExpr) Eval(env *Environment) (interface{}, error) {
 		return nil, err
 	}
 
-	switch TokenType(be.Operator) {
-	case SLASH, STAR, MINUS, LESS, LESS_EQUAL, GREATER, GREATER_EQUAL:
-		lv, ok := leftVal.(float64)
-		rv, ok2 := rightVal.(float64)
-		if !ok || !ok2 {
-			return nil, fmt.Errorf("Operands must be numbers.\n[line %d]", be.Line)
-		}
-
-		switch TokenType(be.Operator) {
-		case SLASH:
-			return lv / rv, nil
-		case STAR:
-			return lv * rv, nil
-		case MINUS:
-			return lv - rv, nil
-		case LESS:
-			return lv < rv, nil
-		case LESS_EQUAL:
-			return lv <= rv, nil
-		case GREATER:
-			return lv > rv, nil
-		case GREATER_EQUAL:
-			return lv >= rv, nil
+	return binaryOperation(TokenType(be.Operator), leftVal, rightVal, be.Line)
+}
+
+// binaryOperation applies a binary operator to its evaluated operands, it is shared by
+// binary expressions and compound assignments.
+func binaryOperation(operator TokenType, leftVal, rightVal interface{}, line int) (interface{}, error) {
+	if ci, ok := leftVal.(*ClassInstance); ok {
+		if hook, ok := binaryOperatorHooks[operator]; ok {
+			ret, found, err := callHook(ci, hook, rightVal)
+			if found || err != nil {
+				return ret, err
+			}
+		}
+	}
+
+	switch operator {
+	case SLASH, STAR, MINUS, PERCENT, LESS, LESS_EQUAL, GREATER, GREATER_EQUAL:
+		if isBigNumber(leftVal) || isBigNumber(rightVal) {
+			switch operator {
+			case SLASH, STAR, MINUS, PERCENT:
+				return bigArithmetic(operator, leftVal, rightVal, line)
+			default:
+				return bigCompare(operator, leftVal, rightVal, line)
+			}
 		}
-	case PLUS:
-		t1 := reflect.TypeOf(leftVal).Kind()
-		t2 := reflect.TypeOf(rightVal).Kind()
 
-		if (t1 != reflect.Float64 && t1 != reflect.String) ||
-			(t2 != reflect.Float64 && t2 != reflect.String) ||
-			(t1 != t2) {
-			return nil, fmt.Errorf("Operands must be two numbers or two strings.\n[line %d]", be.Line)
+		if l, ok := leftVal.(*EnumValue); ok && operator != SLASH && operator != STAR && operator != MINUS && operator != PERCENT {
+			r, ok := rightVal.(*EnumValue)
+			if !ok || r.Enum != l.Enum {
+				return nil, fmt.Errorf("Operands must be values of the same enum.\n[line %d]", line)
+			}
+
+			return compareNumbers(operator, l.Ordinal, r.Ordinal), nil
+		}
+
+		if !isNumber(leftVal) || !isNumber(rightVal) {
+			return nil, fmt.Errorf("Operands must be numbers.\n[line %d]", line)
+		}
+
+		switch operator {
+		case SLASH, STAR, MINUS, PERCENT:
+			return numberArithmetic(operator, leftVal, rightVal), nil
+		default:
+			return compareNumbers(operator, leftVal, rightVal), nil
+		}
+	case PLUS:
+		if isBigNumber(leftVal) || isBigNumber(rightVal) {
+			return bigArithmetic(PLUS, leftVal, rightVal, line)
 		}
 
-		lv, ok := leftVal.(float64)
-		if ok {
-			rv, _ := rightVal.(float64)
-			return lv + rv, nil
+		if isNumber(leftVal) && isNumber(rightVal) {
+			return numberArithmetic(PLUS, leftVal, rightVal), nil
 		}
 
 		lvs, ok := leftVal.(string)
-		if ok {
-			rvs, _ := rightVal.(string)
-			return lvs + rvs, nil
+		rvs, ok2 := rightVal.(string)
+		if !ok || !ok2 {
+			return nil, fmt.Errorf("Operands must be two numbers or two strings.\n[line %d]", line)
 		}
+
+		return lvs + rvs, nil
 	case EQUAL_EQUAL:
-		return leftVal == rightVal, nil
+		return valuesEqual(leftVal, rightVal)
 	case BANG_EQUAL:
-		return leftVal != rightVal, nil
+		eq, err := valuesEqual(leftVal, rightVal)
+		if err != nil {
+			return nil, err
+		}
+
+		return !eq, nil
+	case IS:
+		ok, err := isInstance(leftVal, rightVal)
+		if err != nil {
+			return nil, fmt.Errorf("%w\n[line %d]", err, line)
+		}
+
+		return ok, nil
 	}
 
 	// unreachable
@@ -184,6 +222,17 @@ func (le *LogicalExpr) Eval(env *Environment) (interface{}, error) {
 		}
 
 		return rv, nil
+	case QUESTION_QUESTION:
+		lv, err := le.LeftExpr.Eval(env)
+		if err != nil {
+			return nil, err
+		}
+
+		if lv != nil {
+			return lv, nil
+		}
+
+		return le.RightExpr.Eval(env)
 	}
 
 	// unreachable
@@ -195,6 +244,52 @@ func (le *LogicalExpr) String() string {
 
 }
 
+type ConditionalExpr struct {
+	Cond Expression
+	Then Expression
+	Else Expression
+}
+
+func (ce *ConditionalExpr) Eval(env *Environment) (interface{}, error) {
+	cond, err := ce.Cond.Eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	if isTrue(cond) {
+		return ce.Then.Eval(env)
+	}
+
+	return ce.Else.Eval(env)
+}
+
+func (ce *ConditionalExpr) String() string {
+	return fmt.Sprintf("(?: %v %v %v)", ce.Cond, ce.Then, ce.Else)
+}
+
+// OptionalChainExpr wraps a chain of calls and property accesses which contains a
+// "?.", the chain evaluates to nil when one of them short-circuits.
+type OptionalChainExpr struct {
+	Expr Expression
+}
+
+// errShortCircuit is returned by an optional property access on nil, it unwinds the
+// rest of the chain up to the enclosing OptionalChainExpr.
+var errShortCircuit = errors.New("optional chain short-circuited")
+
+func (oc *OptionalChainExpr) Eval(env *Environment) (interface{}, error) {
+	val, err := oc.Expr.Eval(env)
+	if errors.Is(err, errShortCircuit) {
+		return nil, nil
+	}
+
+	return val, err
+}
+
+func (oc *OptionalChainExpr) String() string {
+	return fmt.Sprintf("%v", oc.Expr)
+}
+
 type GroupingExpr struct {
 	Expr Expression
 	Line int
@@ -238,6 +333,10 @@ func (as *AssignmentExpr) Eval(env *Environment) (interface{}, error) {
 		return nil, fmt.Errorf("Undefined variable '%s'.\n[line %d]", as.Name, as.Line)
 	}
 
+	if varEnv.IsConstant(as.Name) {
+		return nil, fmt.Errorf("Cannot assign to constant '%s'.\n[line %d]", as.Name, as.Line)
+	}
+
 	val, err := as.Expr.Eval(env)
 	if err != nil {
 		return nil, err
@@ -255,10 +354,30 @@ func (as *AssignmentExpr) String() string {
 type CallExpr struct {
 	Callee Expression
 	Args   []Expression
+	Named  []NamedArgument
 	Line   int
 }
 
+// NamedArgument is a "name: value" argument, it is matched to the parameter with the
+// same name.
+type NamedArgument struct {
+	Name string
+	Expr Expression
+	Line int
+}
+
 func (c *CallExpr) Eval(env *Environment) (interface{}, error) {
+	call, err := c.prepare(env)
+	if err != nil {
+		return nil, err
+	}
+
+	return call()
+}
+
+// prepare evaluates and checks the callee and the arguments, the returned function
+// makes the call. Spawning a task evaluates them before the task starts.
+func (c *CallExpr) prepare(env *Environment) (func() (interface{}, error), error) {
 	val, err := c.Callee.Eval(env)
 	if err != nil {
 		return nil, err
@@ -269,29 +388,110 @@ func (c *CallExpr) Eval(env *Environment) (interface{}, error) {
 		return nil, fmt.Errorf("Can only call functions and classes.\n[line %d]", c.Line)
 	}
 
-	arity := caller.Arity()
-	if arity != len(c.Args) {
-		return nil, fmt.Errorf("Expected %d arguments but got %d.\n[line %d]", arity, len(c.Args), c.Line)
+	as, err := evalArguments(env, c.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(c.Named) > 0 {
+		return c.prepareNamed(env, caller, as)
+	}
+
+	if !acceptsArgs(caller, len(as)) {
+		return nil, fmt.Errorf("%s but got %d.\n[line %d]", expectedArgs(caller), len(as), c.Line)
+	}
+
+	return func() (interface{}, error) {
+		ret, err := caller.Call(as...)
+		if err != nil {
+			switch caller.(type) {
+			case *FunCaller:
+			case *ClassCaller:
+				if errors.Is(err, ErrAbstractClass) {
+					return nil, fmt.Errorf("%w\n[line %d]", err, c.Line)
+				}
+			default:
+				// native functions do not know where they were called from
+				return nil, fmt.Errorf("%w\n[line %d]", err, c.Line)
+			}
+		}
+
+		return ret, err
+	}, nil
+}
+
+// prepareNamed prepares a call to a function or a class with named arguments. Natives
+// have no parameter names so they only accept positional arguments.
+func (c *CallExpr) prepareNamed(env *Environment, caller Caller, args []interface{}) (func() (interface{}, error), error) {
+	var fc *FunCaller
+
+	switch callee := caller.(type) {
+	case *FunCaller:
+		fc = callee
+	case *ClassCaller:
+		fc, _ = callee.findMethod("init")
+		if fc == nil {
+			fc = &FunCaller{Name: "init"}
+		}
+	default:
+		return nil, fmt.Errorf("Only functions and classes accept named arguments.\n[line %d]", c.Line)
 	}
 
-	var as []interface{}
+	named := make(map[string]interface{}, len(c.Named))
+
+	for _, arg := range c.Named {
+		i := slices.IndexFunc(fc.Params, func(p Parameter) bool { return p.Name == arg.Name && !p.Rest })
+		if i < 0 {
+			return nil, fmt.Errorf("Unknown argument '%s' for %s.\n[line %d]", arg.Name, caller, arg.Line)
+		}
+
+		if i < len(args) {
+			return nil, fmt.Errorf("Argument '%s' for %s is given more than once.\n[line %d]", arg.Name, caller, arg.Line)
+		}
 
-	for _, arg := range c.Args {
-		v, err := arg.Eval(env)
+		v, err := arg.Expr.Eval(env)
 		if err != nil {
 			return nil, err
 		}
 
-		as = append(as, v)
+		named[arg.Name] = v
+	}
+
+	if _, maxArity := fc.Arity(); maxArity >= 0 && len(args) > maxArity {
+		return nil, fmt.Errorf("Expected at most %s but got %d.\n[line %d]", plural(maxArity, "positional argument"), len(args), c.Line)
+	}
+
+	for _, param := range fc.Params[min(len(args), len(fc.Params)):] {
+		if _, ok := named[param.Name]; !ok && param.Default == nil && !param.Rest {
+			return nil, fmt.Errorf("Missing argument '%s' for %s.\n[line %d]", param.Name, caller, c.Line)
+		}
 	}
 
-	return caller.Call(as...)
+	return func() (interface{}, error) {
+		var (
+			ret interface{}
+			err error
+		)
+
+		if cc, ok := caller.(*ClassCaller); ok {
+			ret, err = cc.call(args, named)
+		} else {
+			ret, err = fc.call(args, named)
+		}
+
+		if errors.Is(err, ErrAbstractClass) {
+			return nil, fmt.Errorf("%w\n[line %d]", err, c.Line)
+		}
+
+		return ret, err
+	}, nil
 }
 
 type ObjectGetExpr struct {
-	Object Expression
-	Prop   string
-	Line   int
+	Object   Expression
+	Prop     string
+	Optional bool
+	Line     int
 }
 
 func (o *ObjectGetExpr) Eval(env *Environment) (interface{}, error) {
@@ -300,177 +500,949 @@ func (o *ObjectGetExpr) Eval(env *Environment) (interface{}, error) {
 		return nil, err
 	}
 
+	if val == nil && o.Optional {
+		return nil, errShortCircuit
+	}
+
+	return getProperty(env, val, o.Prop, o.Line)
+}
+
+// getProperty reads the property prop of val, an instance or a class.
+func getProperty(env *Environment, val interface{}, prop string, line int) (interface{}, error) {
+	if isPrivate(prop) {
+		return getPrivate(env, val, prop, line)
+	}
+
+	if b, ok := val.(builtinValue); ok {
+		m, ok := b.method(prop)
+		if !ok {
+			return nil, fmt.Errorf("%v has no property called %s\n[line %d]", val, prop, line)
+		}
+
+		return m, nil
+	}
+
+	switch v := val.(type) {
+	case *Enum:
+		m, ok := v.property(prop)
+		if !ok {
+			return nil, fmt.Errorf("Enum %s has no value called %s\n[line %d]", v.Name, prop, line)
+		}
+
+		return m, nil
+	case *EnumValue:
+		m, ok := v.property(prop)
+		if !ok {
+			return nil, fmt.Errorf("%v has no property called %s\n[line %d]", v, prop, line)
+		}
+
+		return m, nil
+	}
+
+	if cc, ok := val.(*ClassCaller); ok {
+		m, ok := cc.findStatic(prop)
+		if !ok {
+			return nil, fmt.Errorf("Class %s has no static property called %s\n[line %d]", cc.Name, prop, line)
+		}
+
+		return m, nil
+	}
+
 	obj, ok := val.(*ClassInstance)
 	if !ok {
-		return nil, fmt.Errorf("Invalid operation, %v not an instance of an object.\n[line %d]", val, o.Line)
+		return nil, fmt.Errorf("Invalid operation, %v not an instance of an object.\n[line %d]", val, line)
 	}
 
-	m, ok := obj.findMethod(o.Prop)
+	m, ok := obj.findMethod(prop)
 	if ok {
 		return m, nil
 	}
 
-	m, ok = obj.Properties[o.Prop]
+	if getter, ok := obj.Class.findGetter(prop); ok {
+		return getter.bind(obj).Call()
+	}
+
+	m, ok = obj.Properties[prop]
 	if !ok {
-		return nil, fmt.Errorf("Object %s has no property called %s\n[line %d]", obj.Name, o.Prop, o.Line)
+		return nil, fmt.Errorf("Object %s has no property called %s\n[line %d]", obj.Class.Name, prop, line)
 	}
 
 	return m, nil
 }
 
-type ObjectSetExpr struct {
-	Object Expression
-	Prop   string
-	Expr   Expression
-	Line   int
+// classBinding names the binding methods use to find the class they were declared in.
+// It cannot clash with a variable since it is not a valid identifier.
+const classBinding = "<class>"
+
+// isPrivate reports whether name is the name of a private member, which starts with
+// '#'. Private members are only accessible through "this" inside the methods of the
+// class which declares them.
+func isPrivate(name string) bool {
+	return strings.HasPrefix(name, string(HASH))
 }
 
-func (o *ObjectSetExpr) Eval(env *Environment) (interface{}, error) {
-	val, err := o.Object.Eval(env)
-	if err != nil {
-		return nil, err
+// privateScope returns the class whose private members the code executing in env can
+// access, along with val as an instance.
+func privateScope(env *Environment, val interface{}, name string, line int) (*ClassCaller, *ClassInstance, error) {
+	classEnv, ok := env.Lookup(classBinding)
+	if !ok {
+		return nil, nil, fmt.Errorf("Private member %s can only be accessed inside its class.\n[line %d]", name, line)
 	}
 
 	obj, ok := val.(*ClassInstance)
 	if !ok {
-		return nil, fmt.Errorf("Invalid operation, %v not an instance of an object.\n[line %d]", val, o.Line)
+		return nil, nil, fmt.Errorf("Invalid operation, private member %s of %v can only be accessed on an instance.\n[line %d]", name, val, line)
 	}
 
-	_, found := obj.findMethod(o.Prop)
-	if found {
-		return nil, fmt.Errorf("Invalid operation, cant set a method %s of object %s\n[line %d]", o.Prop, obj.Name, o.Line)
-	}
+	return classEnv.Bindings[classBinding].(*ClassCaller), obj, nil
+}
 
-	val, err = o.Expr.Eval(env)
+func getPrivate(env *Environment, val interface{}, name string, line int) (interface{}, error) {
+	cc, obj, err := privateScope(env, val, name, line)
 	if err != nil {
 		return nil, err
 	}
 
-	obj.Properties[o.Prop] = val
+	if m, ok := cc.Methods[name]; ok {
+		return m.bind(obj), nil
+	}
 
-	return nil, nil
-}
+	v, ok := obj.Private[cc][name]
+	if !ok {
+		return nil, fmt.Errorf("Object %s has no private member called %s\n[line %d]", obj.Class.Name, name, line)
+	}
 
-type Statement interface {
-	Execute(env *Environment) (interface{}, error)
+	return v, nil
 }
 
-type NilStmt struct{}
-
-func (ns *NilStmt) Execute(_ *Environment) (interface{}, error) { return nil, nil }
-
-type ClassDeclStmt struct {
-	Name       string
-	SuperClass *IdentifierExpr
-	Methods    []*FunDeclStmt
+// SuperExpr is a "super.method" access, the method is looked up starting at the
+// superclass of the class the enclosing method was declared in and is bound to the
+// current "this".
+type SuperExpr struct {
+	Method string
+	Line   int
 }
 
-func (c *ClassDeclStmt) Execute(env *Environment) (interface{}, error) {
-	cc := ClassCaller{
-		Name:    c.Name,
-		Methods: c.Methods,
-		closure: env,
+func (s *SuperExpr) Eval(env *Environment) (interface{}, error) {
+	superEnv, ok := env.Lookup("super")
+	if !ok {
+		return nil, fmt.Errorf("Can't use 'super' in a class with no superclass.\n[line %d]", s.Line)
 	}
 
-	if c.SuperClass != nil {
-		sc, err := c.SuperClass.Eval(env)
-		if err != nil {
-			return nil, err
-		}
+	thisEnv, ok := env.Lookup("this")
+	if !ok {
+		return nil, fmt.Errorf("Can't use 'super' outside of a class.\n[line %d]", s.Line)
+	}
 
-		v, ok := sc.(*ClassCaller)
-		if !ok {
-			return nil, fmt.Errorf("%s must be of class type.\n[line %d]", c.SuperClass.Name, c.SuperClass.Line)
-		}
+	superClass := superEnv.Bindings["super"].(*ClassCaller)
+	this := thisEnv.Bindings["this"]
 
-		cc.SuperClass = v
+	// inside static methods "this" is the class itself
+	findMethod := superClass.findMethod
+	if _, ok := this.(*ClassCaller); ok {
+		findMethod = superClass.findStaticMethod
 	}
 
-	for _, m := range cc.Methods {
-		if m.Name == "init" {
-			cc.arity = len(m.Params)
-			break
-		}
+	m, ok := findMethod(s.Method)
+	if !ok {
+		return nil, fmt.Errorf("Undefined property '%s'.\n[line %d]", s.Method, s.Line)
 	}
 
-	env.SetBinding(c.Name, &cc)
+	return m.bind(this), nil
+}
 
-	return nil, nil
+func (s *SuperExpr) String() string {
+	return fmt.Sprintf("super.%s", s.Method)
 }
 
-type FunDeclStmt struct {
-	Name   string
-	Params []IdentifierExpr
-	Body   Statement
+type IndexExpr struct {
+	Object Expression
+	Index  Expression
+	Line   int
 }
 
-func (f *FunDeclStmt) Execute(env *Environment) (interface{}, error) {
-	fc := FunCaller{
-		Name:    f.Name,
-		Params:  f.Params,
-		Body:    f.Body,
-		closure: env,
+func (ie *IndexExpr) Eval(env *Environment) (interface{}, error) {
+	val, err := ie.Object.Eval(env)
+	if err != nil {
+		return nil, err
 	}
 
-	env.SetBinding(f.Name, &fc)
+	idx, err := ie.Index.Eval(env)
+	if err != nil {
+		return nil, err
+	}
 
-	return &fc, nil
+	return getIndex(val, idx, ie.Line)
 }
 
-type VarDeclStmt struct {
-	Name string
-	Expr Expression
-}
+func getIndex(val, idx interface{}, line int) (interface{}, error) {
+	switch v := val.(type) {
+	case *List:
+		i, ok := index(idx, len(v.Elements))
+		if !ok {
+			return nil, fmt.Errorf("List index %v out of range.\n[line %d]", idx, line)
+		}
 
-func (v *VarDeclStmt) Execute(env *Environment) (interface{}, error) {
-	val, err := v.Expr.Eval(env)
-	if err != nil {
-		return nil, err
-	}
+		return v.Elements[i], nil
+	case string:
+		i, ok := index(idx, len(v))
+		if !ok {
+			return nil, fmt.Errorf("String index %v out of range.\n[line %d]", idx, line)
+		}
 
-	env.SetBinding(v.Name, val)
+		return v[i : i+1], nil
+	case *ClassInstance:
+		ret, found, err := callHook(v, "__index", idx)
+		if found || err != nil {
+			return ret, err
+		}
+	}
 
-	return nil, nil
+	return nil, fmt.Errorf("Invalid operation, %v cannot be indexed.\n[line %d]", val, line)
 }
 
-type ExprStmt struct {
-	Expr Expression
+func (ie *IndexExpr) String() string {
+	return fmt.Sprintf("(index %v %v)", ie.Object, ie.Index)
 }
 
-func (es *ExprStmt) Execute(env *Environment) (interface{}, error) {
-	return es.Expr.Eval(env)
+type IndexSetExpr struct {
+	Object Expression
+	Index  Expression
+	Expr   Expression
+	Line   int
 }
 
-type PrintStmt struct {
-	Expr Expression
-}
+func (is *IndexSetExpr) Eval(env *Environment) (interface{}, error) {
+	target, err := is.Object.Eval(env)
+	if err != nil {
+		return nil, err
+	}
 
-func (ps *PrintStmt) Execute(env *Environment) (interface{}, error) {
-	val, err := ps.Expr.Eval(env)
+	idx, err := is.Index.Eval(env)
 	if err != nil {
 		return nil, err
 	}
 
-	fmt.Println(val)
+	val, err := is.Expr.Eval(env)
+	if err != nil {
+		return nil, err
+	}
 
-	return nil, nil
+	err = setIndex(target, idx, val, is.Line)
+	if err != nil {
+		return nil, err
+	}
+
+	return val, nil
 }
 
-type BlockStmt struct {
-	Stmts []Statement
+func (is *IndexSetExpr) String() string {
+	return fmt.Sprintf("(index= %v %v %v)", is.Object, is.Index, is.Expr)
 }
 
-func (b *BlockStmt) Execute(env *Environment) (interface{}, error) {
-	localEnv := ExpandEnv(env)
+func setIndex(target, idx, val interface{}, line int) error {
+	switch v := target.(type) {
+	case *List:
+		i, ok := index(idx, len(v.Elements))
+		if !ok {
+			return fmt.Errorf("List index %v out of range.\n[line %d]", idx, line)
+		}
 
-	for _, stmt := range b.Stmts {
-		_, err := stmt.Execute(localEnv)
-		if err != nil {
-			return nil, err
+		v.Elements[i] = val
+
+		return nil
+	case *ClassInstance:
+		if v.Frozen {
+			return fmt.Errorf("Invalid operation, object %s is frozen\n[line %d]", v.Class.Name, line)
+		}
+
+		_, found, err := callHook(v, "__setindex", idx, val)
+		if found || err != nil {
+			return err
 		}
 	}
 
-	return nil, nil
+	return fmt.Errorf("Invalid operation, %v does not support index assignment.\n[line %d]", target, line)
+}
+
+// UpdateExpr is a compound assignment like "x += 1" or an increment or decrement like
+// "x++", to a variable, a property or an indexed element. The object and the index of
+// the target are evaluated only once. Expr is nil for increments and decrements,
+// Postfix ones evaluate to the value the target had before.
+type UpdateExpr struct {
+	Target   Expression
+	Operator TokenType
+	Expr     Expression
+	Postfix  bool
+	Line     int
+}
+
+func (u *UpdateExpr) Eval(env *Environment) (interface{}, error) {
+	switch target := u.Target.(type) {
+	case *IdentifierExpr:
+		varEnv, ok := env.Lookup(target.Name)
+		if !ok {
+			return nil, fmt.Errorf("Undefined variable '%s'.\n[line %d]", target.Name, target.Line)
+		}
+
+		if varEnv.IsConstant(target.Name) {
+			return nil, fmt.Errorf("Cannot assign to constant '%s'.\n[line %d]", target.Name, target.Line)
+		}
+
+		old := varEnv.Bindings[target.Name]
+
+		val, err := u.apply(env, old)
+		if err != nil {
+			return nil, err
+		}
+
+		varEnv.SetBinding(target.Name, val)
+
+		return u.result(old, val), nil
+	case *ObjectGetExpr:
+		obj, err := target.Object.Eval(env)
+		if err != nil {
+			return nil, err
+		}
+
+		old, err := getProperty(env, obj, target.Prop, target.Line)
+		if err != nil {
+			return nil, err
+		}
+
+		val, err := u.apply(env, old)
+		if err != nil {
+			return nil, err
+		}
+
+		err = setProperty(env, obj, target.Prop, val, target.Line)
+		if err != nil {
+			return nil, err
+		}
+
+		return u.result(old, val), nil
+	case *IndexExpr:
+		obj, err := target.Object.Eval(env)
+		if err != nil {
+			return nil, err
+		}
+
+		idx, err := target.Index.Eval(env)
+		if err != nil {
+			return nil, err
+		}
+
+		old, err := getIndex(obj, idx, target.Line)
+		if err != nil {
+			return nil, err
+		}
+
+		val, err := u.apply(env, old)
+		if err != nil {
+			return nil, err
+		}
+
+		err = setIndex(obj, idx, val, target.Line)
+		if err != nil {
+			return nil, err
+		}
+
+		return u.result(old, val), nil
+	}
+
+	// unreachable, the parser only accepts the targets above
+	return nil, fmt.Errorf("Invalid assignment target.\n[line %d]", u.Line)
+}
+
+func (u *UpdateExpr) apply(env *Environment, old interface{}) (interface{}, error) {
+	if u.Expr == nil {
+		// like unary minus, increments only apply to numbers and to instances which
+		// overload the operator
+		if ci, ok := old.(*ClassInstance); ok {
+			ret, found, err := callHook(ci, binaryOperatorHooks[u.Operator], int64(1))
+			if found || err != nil {
+				return ret, err
+			}
+		}
+
+		if !isNumber(old) && !isBigNumber(old) {
+			return nil, fmt.Errorf("Operand must be a number.\n[line %d]", u.Line)
+		}
+
+		return binaryOperation(u.Operator, old, int64(1), u.Line)
+	}
+
+	operand, err := u.Expr.Eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	return binaryOperation(u.Operator, old, operand, u.Line)
+}
+
+func (u *UpdateExpr) result(old, val interface{}) interface{} {
+	if u.Postfix {
+		return old
+	}
+
+	return val
+}
+
+func (u *UpdateExpr) String() string {
+	if u.Expr == nil {
+		return fmt.Sprintf("(%s%s %v)", u.Operator, u.Operator, u.Target)
+	}
+
+	return fmt.Sprintf("(%s= %v %v)", u.Operator, u.Target, u.Expr)
+}
+
+type ObjectSetExpr struct {
+	Object Expression
+	Prop   string
+	Expr   Expression
+	Line   int
+}
+
+func (o *ObjectSetExpr) Eval(env *Environment) (interface{}, error) {
+	target, err := o.Object.Eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	val, err := o.Expr.Eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, setProperty(env, target, o.Prop, val, o.Line)
+}
+
+// setProperty writes val to the property prop of target, an instance or a class.
+func setProperty(env *Environment, target interface{}, prop string, val interface{}, line int) error {
+	if obj, ok := target.(*ClassInstance); ok && obj.Frozen {
+		return fmt.Errorf("Invalid operation, object %s is frozen\n[line %d]", obj.Class.Name, line)
+	}
+
+	if isPrivate(prop) {
+		cc, obj, err := privateScope(env, target, prop, line)
+		if err != nil {
+			return err
+		}
+
+		if _, found := cc.Methods[prop]; found {
+			return fmt.Errorf("Invalid operation, cant set a method %s of object %s\n[line %d]", prop, obj.Class.Name, line)
+		}
+
+		if obj.Private[cc] == nil {
+			obj.Private[cc] = make(map[string]interface{})
+		}
+
+		obj.Private[cc][prop] = val
+
+		return nil
+	}
+
+	setter, fields, err := fieldAssignment(target, prop)
+	if err != nil {
+		return fmt.Errorf("%w\n[line %d]", err, line)
+	}
+
+	if setter != nil {
+		_, err := setter.Call(val)
+		return err
+	}
+
+	fields[prop] = val
+
+	return nil
+}
+
+// fieldAssignment checks prop of target can be assigned the way an assignment does:
+// methods cannot be overwritten and a property with a getter needs a setter. It
+// returns the setter bound to target when there is one, and the fields to store the
+// value in otherwise.
+func fieldAssignment(target interface{}, prop string) (Caller, map[string]interface{}, error) {
+	if cc, ok := target.(*ClassCaller); ok {
+		_, found := cc.findStaticMethod(prop)
+		if found {
+			return nil, nil, fmt.Errorf("Invalid operation, cant set a static method %s of class %s", prop, cc.Name)
+		}
+
+		return nil, cc.StaticFields, nil
+	}
+
+	obj, ok := target.(*ClassInstance)
+	if !ok {
+		return nil, nil, fmt.Errorf("Invalid operation, %v not an instance of an object.", target)
+	}
+
+	_, found := obj.findMethod(prop)
+	if found {
+		return nil, nil, fmt.Errorf("Invalid operation, cant set a method %s of object %s", prop, obj.Class.Name)
+	}
+
+	setter, hasSetter := obj.Class.findSetter(prop)
+	_, hasGetter := obj.Class.findGetter(prop)
+	if hasGetter && !hasSetter {
+		return nil, nil, fmt.Errorf("Invalid operation, property %s of object %s has no setter", prop, obj.Class.Name)
+	}
+
+	if hasSetter {
+		return setter.bind(obj), nil, nil
+	}
+
+	return nil, obj.Properties, nil
+}
+
+type Statement interface {
+	Execute(env *Environment) (interface{}, error)
+}
+
+type NilStmt struct{}
+
+func (ns *NilStmt) Execute(_ *Environment) (interface{}, error) { return nil, nil }
+
+// ClassMembers are the instance members shared by class and trait declarations.
+// Abstract methods have no body. Fields only declare the type of a field for the
+// checker, instances get their fields by assignment.
+type ClassMembers struct {
+	Fields   []*FieldDecl
+	Methods  []*FunDeclStmt
+	Getters  []*FunDeclStmt
+	Setters  []*FunDeclStmt
+	Abstract []*FunDeclStmt
+}
+
+type ClassDeclStmt struct {
+	Name       string
+	SuperClass *IdentifierExpr
+	Traits     []*IdentifierExpr
+	Interfaces []*IdentifierExpr
+	ClassMembers
+	StaticMethods []*FunDeclStmt
+	StaticFields  []*VarDeclStmt
+}
+
+func (c *ClassDeclStmt) Execute(env *Environment) (interface{}, error) {
+	cc := ClassCaller{
+		Name:          c.Name,
+		Methods:       make(map[string]*FunCaller),
+		Getters:       make(map[string]*FunCaller),
+		Setters:       make(map[string]*FunCaller),
+		Abstract:      make(map[string]int),
+		StaticMethods: make(map[string]*FunCaller),
+		StaticFields:  make(map[string]interface{}),
+	}
+
+	if c.SuperClass != nil {
+		sc, err := c.SuperClass.Eval(env)
+		if err != nil {
+			return nil, err
+		}
+
+		v, ok := sc.(*ClassCaller)
+		if !ok {
+			return nil, fmt.Errorf("%s must be of class type.\n[line %d]", c.SuperClass.Name, c.SuperClass.Line)
+		}
+
+		cc.SuperClass = v
+	}
+
+	layers, err := c.traitLayers(env, &cc)
+	if err != nil {
+		return nil, err
+	}
+
+	super := cc.SuperClass
+	if len(layers) > 0 {
+		super = layers[len(layers)-1]
+	}
+
+	// methods close over an environment binding "super" to the superclass, so that
+	// super calls resolve relative to the class a method was declared in, and the
+	// class itself, which scopes its private members
+	methodEnv := ExpandEnv(env)
+	methodEnv.SetBinding(classBinding, &cc)
+
+	if super != nil {
+		methodEnv.SetBinding("super", super)
+	}
+
+	for _, methods := range []struct {
+		decls []*FunDeclStmt
+		dest  map[string]*FunCaller
+	}{
+		{c.Methods, cc.Methods},
+		{c.Getters, cc.Getters},
+		{c.Setters, cc.Setters},
+		{c.StaticMethods, cc.StaticMethods},
+	} {
+		addMethods(methods.dest, methods.decls, methodEnv)
+	}
+
+	err = c.copyTraitMembers(&cc, layers)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, m := range c.Abstract {
+		cc.Abstract[m.Name] = len(m.Params)
+	}
+
+	err = c.checkImplementations(&cc, layers)
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.checkInterfaces(env, &cc)
+	if err != nil {
+		return nil, err
+	}
+
+	// the class is bound before evaluating static fields so their initializers can
+	// refer to it
+	env.SetBinding(c.Name, &cc)
+
+	for _, f := range c.StaticFields {
+		val, err := f.initialValue(methodEnv)
+		if err != nil {
+			return nil, err
+		}
+
+		cc.StaticFields[f.Name] = val
+	}
+
+	return nil, nil
+}
+
+// traitLayers turns every trait the class is composed with into a class of its own,
+// each one inheriting from the previous one and the first from the superclass. Trait
+// methods bind "super" to the layer below theirs, which makes super calls inside them
+// reach the next trait providing the method, or the superclass.
+func (c *ClassDeclStmt) traitLayers(env *Environment, cc *ClassCaller) ([]*ClassCaller, error) {
+	var layers []*ClassCaller
+
+	prev := cc.SuperClass
+
+	for _, t := range c.Traits {
+		val, err := t.Eval(env)
+		if err != nil {
+			return nil, err
+		}
+
+		trait, ok := val.(*Trait)
+		if !ok {
+			return nil, fmt.Errorf("%s must be a trait.\n[line %d]", t.Name, t.Line)
+		}
+
+		cc.Traits = append(cc.Traits, trait)
+
+		methodEnv := trait.closure
+		if prev != nil {
+			methodEnv = ExpandEnv(trait.closure)
+			methodEnv.SetBinding("super", prev)
+		}
+
+		layer := &ClassCaller{
+			Name:       trait.Name,
+			SuperClass: prev,
+			Methods:    make(map[string]*FunCaller),
+			Getters:    make(map[string]*FunCaller),
+			Setters:    make(map[string]*FunCaller),
+		}
+
+		addMethods(layer.Methods, trait.Methods, methodEnv)
+		addMethods(layer.Getters, trait.Getters, methodEnv)
+		addMethods(layer.Setters, trait.Setters, methodEnv)
+
+		layer.Abstract = make(map[string]int)
+		for _, m := range trait.Abstract {
+			layer.Abstract[m.Name] = len(m.Params)
+		}
+
+		layers = append(layers, layer)
+		prev = layer
+	}
+
+	return layers, nil
+}
+
+// copyTraitMembers copies into the class every trait member it does not define itself.
+// Two traits providing the same member is a conflict the class has to resolve by
+// overriding it.
+func (c *ClassDeclStmt) copyTraitMembers(cc *ClassCaller, layers []*ClassCaller) error {
+	for _, members := range []struct {
+		kind   string
+		dest   map[string]*FunCaller
+		source func(*ClassCaller) map[string]*FunCaller
+	}{
+		{"method", cc.Methods, func(l *ClassCaller) map[string]*FunCaller { return l.Methods }},
+		{"getter", cc.Getters, func(l *ClassCaller) map[string]*FunCaller { return l.Getters }},
+		{"setter", cc.Setters, func(l *ClassCaller) map[string]*FunCaller { return l.Setters }},
+	} {
+		own := make(map[string]bool)
+		for name := range members.dest {
+			own[name] = true
+		}
+
+		providers := make(map[string]string)
+
+		for i, layer := range layers {
+			for name, m := range members.source(layer) {
+				if own[name] {
+					continue
+				}
+
+				if provider, ok := providers[name]; ok {
+					return fmt.Errorf("Class %s gets %s %s from both %s and %s, it must override it.\n[line %d]", c.Name, members.kind, name, provider, layer.Name, c.Traits[i].Line)
+				}
+
+				providers[name] = layer.Name
+				members.dest[name] = m
+			}
+		}
+	}
+
+	// methods a trait requires but neither the class, another trait nor the
+	// superclass provide make the class abstract
+	for _, layer := range layers {
+		for name, arity := range layer.Abstract {
+			if _, ok := cc.Methods[name]; ok {
+				continue
+			}
+
+			if cc.SuperClass != nil {
+				if _, ok := cc.SuperClass.findMethod(name); ok {
+					continue
+				}
+			}
+
+			cc.Abstract[name] = arity
+		}
+	}
+
+	return nil
+}
+
+// checkImplementations verifies the methods implementing the abstract methods of the
+// superclass and of the traits accept as many arguments as the abstract methods do.
+func (c *ClassDeclStmt) checkImplementations(cc *ClassCaller, layers []*ClassCaller) error {
+	if cc.SuperClass != nil {
+		for _, name := range sortedKeys(cc.Methods) {
+			arity, ok := cc.SuperClass.findAbstract(name)
+			if ok && !acceptsArgs(cc.Methods[name], arity) {
+				return fmt.Errorf("Class %s must implement abstract method %s of %s accepting %s, it %s.\n[line %d]", c.Name, name, cc.SuperClass.Name, plural(arity, "argument"), strings.ToLower(expectedArgs(cc.Methods[name])), c.SuperClass.Line)
+			}
+		}
+	}
+
+	for i, layer := range layers {
+		for _, name := range sortedKeys(layer.Abstract) {
+			impl, ok := cc.Methods[name]
+			if !ok && cc.SuperClass != nil {
+				impl, ok = cc.SuperClass.findMethod(name)
+			}
+
+			arity := layer.Abstract[name]
+			if ok && !acceptsArgs(impl, arity) {
+				return fmt.Errorf("Class %s must implement abstract method %s of %s accepting %s, it %s.\n[line %d]", c.Name, name, layer.Name, plural(arity, "argument"), strings.ToLower(expectedArgs(impl)), c.Traits[i].Line)
+			}
+		}
+	}
+
+	return nil
+}
+
+// sortedKeys returns the names of m in order, so checks over it report the same
+// error every run.
+func sortedKeys[V any](m map[string]V) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	return names
+}
+
+// checkInterfaces verifies the class provides every method of the interfaces it
+// implements, either directly or through an abstract method left to its subclasses.
+func (c *ClassDeclStmt) checkInterfaces(env *Environment, cc *ClassCaller) error {
+	for _, i := range c.Interfaces {
+		val, err := i.Eval(env)
+		if err != nil {
+			return err
+		}
+
+		iface, ok := val.(*Interface)
+		if !ok {
+			return fmt.Errorf("%s must be an interface.\n[line %d]", i.Name, i.Line)
+		}
+
+		cc.Interfaces = append(cc.Interfaces, iface)
+
+		for _, m := range iface.Methods {
+			arity := len(m.Params)
+
+			if impl, ok := cc.findMethod(m.Name); ok {
+				if !acceptsArgs(impl, arity) {
+					return fmt.Errorf("Class %s must implement method %s of interface %s accepting %s, it %s.\n[line %d]", c.Name, m.Name, iface.Name, plural(arity, "argument"), strings.ToLower(expectedArgs(impl)), i.Line)
+				}
+
+				continue
+			}
+
+			if a, ok := cc.findAbstract(m.Name); ok && a == arity {
+				continue
+			}
+
+			return fmt.Errorf("Class %s does not implement method %s of interface %s.\n[line %d]", c.Name, m.Name, iface.Name, i.Line)
+		}
+	}
+
+	return nil
+}
+
+type InterfaceDeclStmt struct {
+	Name    string
+	Methods []*FunDeclStmt
+}
+
+func (i *InterfaceDeclStmt) Execute(env *Environment) (interface{}, error) {
+	env.SetBinding(i.Name, &Interface{
+		Name:    i.Name,
+		Methods: i.Methods,
+	})
+
+	return nil, nil
+}
+
+type TraitDeclStmt struct {
+	Name string
+	ClassMembers
+}
+
+func (t *TraitDeclStmt) Execute(env *Environment) (interface{}, error) {
+	env.SetBinding(t.Name, &Trait{
+		Name:         t.Name,
+		ClassMembers: t.ClassMembers,
+		closure:      env,
+	})
+
+	return nil, nil
+}
+
+type FunDeclStmt struct {
+	Name       string
+	Params     []Parameter
+	ReturnType *TypeAnnotation
+	Body       Statement
+	Generator  bool
+}
+
+// Parameter is a function parameter. Parameters with a Default value are optional, the
+// default is evaluated on every call which omits them. A Rest parameter comes last and
+// collects the remaining arguments into a list.
+type Parameter struct {
+	Name    string
+	Line    int
+	Type    *TypeAnnotation
+	Default Expression
+	Rest    bool
+}
+
+func (f *FunDeclStmt) Execute(env *Environment) (interface{}, error) {
+	fc := FunCaller{
+		Name:      f.Name,
+		Params:    f.Params,
+		Body:      f.Body,
+		Generator: f.Generator,
+		closure:   env,
+	}
+
+	env.SetBinding(f.Name, &fc)
+
+	return &fc, nil
+}
+
+// VarDeclStmt declares a variable, or a static field of a class. Expr is nil when
+// there is no initializer, the variable is then nil.
+type VarDeclStmt struct {
+	Name  string
+	Type  *TypeAnnotation
+	Expr  Expression
+	Const bool
+}
+
+func (v *VarDeclStmt) Execute(env *Environment) (interface{}, error) {
+	val, err := v.initialValue(env)
+	if err != nil {
+		return nil, err
+	}
+
+	if v.Const {
+		env.SetConstant(v.Name, val)
+		return nil, nil
+	}
+
+	env.SetBinding(v.Name, val)
+
+	return nil, nil
+}
+
+func (v *VarDeclStmt) initialValue(env *Environment) (interface{}, error) {
+	if v.Expr == nil {
+		return nil, nil
+	}
+
+	return v.Expr.Eval(env)
+}
+
+type ExprStmt struct {
+	Expr Expression
+}
+
+func (es *ExprStmt) Execute(env *Environment) (interface{}, error) {
+	return es.Expr.Eval(env)
+}
+
+type PrintStmt struct {
+	Expr Expression
+	Line int
+}
+
+func (ps *PrintStmt) Execute(env *Environment) (interface{}, error) {
+	val, err := ps.Expr.Eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := stringifyAt(val, ps.Line)
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Println(s)
+
+	return nil, nil
+}
+
+type BlockStmt struct {
+	Stmts []Statement
+}
+
+func (b *BlockStmt) Execute(env *Environment) (interface{}, error) {
+	localEnv := ExpandEnv(env)
+
+	for _, stmt := range b.Stmts {
+		_, err := stmt.Execute(localEnv)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return nil, nil
 }
 
 type IfStmt struct {
@@ -521,6 +1493,7 @@ type ReturnValue struct {
 
 type ReturnStmt struct {
 	Expr Expression
+	Line int
 }
 
 func (rs *ReturnStmt) Execute(env *Environment) (interface{}, error) {
@@ -534,125 +1507,241 @@ func (rs *ReturnStmt) Execute(env *Environment) (interface{}, error) {
 	panic(&ReturnValue{Value: val})
 }
 
+// Caller is anything that can be called. Arity returns the minimum and maximum number
+// of arguments it accepts, the maximum is -1 when there is no limit.
 type Caller interface {
 	Call(args ...interface{}) (interface{}, error)
-	Arity() int
+	Arity() (int, int)
+}
+
+func acceptsArgs(c Caller, n int) bool {
+	minArity, maxArity := c.Arity()
+
+	return n >= minArity && (maxArity < 0 || n <= maxArity)
+}
+
+// expectedArgs describes how many arguments c accepts, for error messages.
+func expectedArgs(c Caller) string {
+	minArity, maxArity := c.Arity()
+
+	switch {
+	case minArity == maxArity:
+		return fmt.Sprintf("Expected %d arguments", minArity)
+	case maxArity < 0:
+		return fmt.Sprintf("Expected at least %s", plural(minArity, "argument"))
+	default:
+		return fmt.Sprintf("Expected %d to %s", minArity, plural(maxArity, "argument"))
+	}
+}
+
+// plural is "1 noun", or "n nouns" for any other n.
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+
+	return fmt.Sprintf("%d %ss", n, noun)
 }
 
 type NativeClock struct{}
 
 func (nc *NativeClock) Call(_ ...interface{}) (interface{}, error) {
-	return float64(time.Now().Unix()), nil
+	return time.Now().Unix(), nil
 }
 
-func (nc *NativeClock) Arity() int { return 0 }
+func (nc *NativeClock) Arity() (int, int) { return 0, 0 }
 
 func (nc *NativeClock) String() string {
 	return "<native fn>"
 }
 
+// ClassInstance holds the public fields of an instance in Properties, private fields
+// are kept apart for each class in the hierarchy so that a subclass cannot reach the
+// private fields of its superclass.
 type ClassInstance struct {
-	Name               string
-	SuperClassInstance *ClassInstance
-	Methods            map[string]interface{}
-	Properties         map[string]interface{}
+	Class      *ClassCaller
+	Properties map[string]interface{}
+	Private    map[*ClassCaller]map[string]interface{}
+
+	// Frozen instances reject any change to their fields.
+	Frozen bool
 }
 
 func (ci *ClassInstance) String() string {
-	return fmt.Sprintf("%s instance", ci.Name)
+	return fmt.Sprintf("%s instance", ci.Class.Name)
 }
 
+// findMethod returns the method called name of the instance's class, bound to the
+// instance.
 func (ci *ClassInstance) findMethod(name string) (interface{}, bool) {
-	m, ok := ci.Methods[name]
-	if ok {
-		return m, true
+	m, ok := ci.Class.findMethod(name)
+	if !ok {
+		return nil, false
 	}
 
-	if ci.SuperClassInstance != nil {
-		return ci.SuperClassInstance.findMethod(name)
-	}
+	return m.bind(ci), true
+}
 
-	return nil, false
+// Interface lists the methods, with their number of parameters, a class declaring to
+// implement it must have.
+type Interface struct {
+	Name    string
+	Methods []*FunDeclStmt
 }
 
-func (ci *ClassInstance) initSuperClass(env *Environment, superClass *ClassCaller) (*ClassInstance, error) {
-	if superClass == nil {
-		return nil, nil
-	}
+func (i *Interface) String() string {
+	return fmt.Sprintf("<interface %s>", i.Name)
+}
+
+// Trait is a named set of methods which classes can be composed with.
+type Trait struct {
+	Name string
+	ClassMembers
+
+	closure *Environment
+}
 
-	sci := ClassInstance{
-		Name:    superClass.Name,
-		Methods: make(map[string]interface{}),
+func (t *Trait) String() string {
+	return fmt.Sprintf("<trait %s>", t.Name)
+}
+
+// ClassCaller is a class at runtime. Methods are created once, when the class is
+// declared, and get bound to an instance whenever they are accessed through it.
+// Static methods are bound to the class itself instead, static methods and fields are
+// inherited by subclasses. Getters and setters are methods called when reading or
+// writing the property of the same name. Abstract maps the abstract methods declared by
+// the class to their number of parameters, a class cannot be instantiated while one of
+// them, its own or inherited, is not implemented.
+type ClassCaller struct {
+	Name          string
+	SuperClass    *ClassCaller
+	Methods       map[string]*FunCaller
+	Getters       map[string]*FunCaller
+	Setters       map[string]*FunCaller
+	Abstract      map[string]int
+	StaticMethods map[string]*FunCaller
+	StaticFields  map[string]interface{}
+	Traits        []*Trait
+	Interfaces    []*Interface
+}
+
+// findAbstract returns the number of parameters of name when it is an abstract method
+// of the class, that is when it is declared abstract before any implementation is
+// found walking up the class hierarchy.
+func (cc *ClassCaller) findAbstract(name string) (int, bool) {
+	for curr := cc; curr != nil; curr = curr.SuperClass {
+		if _, ok := curr.Methods[name]; ok {
+			return 0, false
+		}
+
+		if arity, ok := curr.Abstract[name]; ok {
+			return arity, true
+		}
 	}
 
-	i, err := sci.initSuperClass(env, superClass.SuperClass)
-	if err != nil {
-		return nil, err
+	return 0, false
+}
+
+// unimplemented returns the name of an abstract method of the class with no
+// implementation, if there is one.
+func (cc *ClassCaller) unimplemented() (string, bool) {
+	var names []string
+
+	for curr := cc; curr != nil; curr = curr.SuperClass {
+		for name := range curr.Abstract {
+			if _, ok := cc.findAbstract(name); ok {
+				names = append(names, name)
+			}
+		}
 	}
 
-	localEnv := ExpandEnv(env)
-	localEnv.SetBinding("super", nil)
-	if i != nil {
-		localEnv.SetBinding("super", i)
-		sci.SuperClassInstance = i
+	if len(names) == 0 {
+		return "", false
 	}
 
-	for _, m := range superClass.Methods {
-		val, err := m.Execute(localEnv)
-		if err != nil {
-			return nil, err
-		}
+	slices.Sort(names)
 
-		sci.Methods[m.Name] = val
+	return names[0], true
+}
+
+func (cc *ClassCaller) findGetter(name string) (*FunCaller, bool) {
+	for curr := cc; curr != nil; curr = curr.SuperClass {
+		if m, ok := curr.Getters[name]; ok {
+			return m, true
+		}
 	}
 
-	return &sci, nil
+	return nil, false
 }
 
-type ClassCaller struct {
-	Name       string
-	SuperClass *ClassCaller
-	Methods    []*FunDeclStmt
+func (cc *ClassCaller) findSetter(name string) (*FunCaller, bool) {
+	for curr := cc; curr != nil; curr = curr.SuperClass {
+		if m, ok := curr.Setters[name]; ok {
+			return m, true
+		}
+	}
 
-	arity   int
-	closure *Environment
+	return nil, false
 }
 
-func (cc *ClassCaller) Call(args ...interface{}) (interface{}, error) {
-	ci := ClassInstance{
-		Name:       cc.Name,
-		Methods:    map[string]interface{}{},
-		Properties: make(map[string]interface{}),
+func (cc *ClassCaller) findStaticMethod(name string) (*FunCaller, bool) {
+	for curr := cc; curr != nil; curr = curr.SuperClass {
+		if m, ok := curr.StaticMethods[name]; ok {
+			return m, true
+		}
 	}
 
-	localEnv := ExpandEnv(cc.closure)
+	return nil, false
+}
 
-	// TODO: make sure params cannot shadow "this" keyword
-	localEnv.SetBinding("this", &ci)
+// findStatic looks up a static property of the class, static methods are bound to the
+// class the lookup started from.
+func (cc *ClassCaller) findStatic(name string) (interface{}, bool) {
+	if m, ok := cc.findStaticMethod(name); ok {
+		return m.bind(cc), true
+	}
 
-	sci, err := ci.initSuperClass(localEnv, cc.SuperClass)
-	if err != nil {
-		return nil, err
+	for curr := cc; curr != nil; curr = curr.SuperClass {
+		if v, ok := curr.StaticFields[name]; ok {
+			return v, true
+		}
 	}
 
-	localEnv.SetBinding("super", nil)
-	if sci != nil {
-		localEnv.SetBinding("super", sci)
-		ci.SuperClassInstance = sci
+	return nil, false
+}
+
+func (cc *ClassCaller) findMethod(name string) (*FunCaller, bool) {
+	if isPrivate(name) {
+		return nil, false
 	}
 
-	for _, m := range cc.Methods {
-		val, err := m.Execute(localEnv)
-		if err != nil {
-			return nil, err
+	for curr := cc; curr != nil; curr = curr.SuperClass {
+		if m, ok := curr.Methods[name]; ok {
+			return m, true
 		}
+	}
 
-		ci.Methods[m.Name] = val
+	return nil, false
+}
+
+func (cc *ClassCaller) Call(args ...interface{}) (interface{}, error) {
+	return cc.call(args, nil)
+}
+
+// call creates an instance, named holds the arguments of init passed by name.
+func (cc *ClassCaller) call(args []interface{}, named map[string]interface{}) (interface{}, error) {
+	if name, ok := cc.unimplemented(); ok {
+		return nil, fmt.Errorf("%w %s, method %s is not implemented.", ErrAbstractClass, cc.Name, name)
 	}
 
-	if v, ok := ci.Methods["init"]; ok {
-		initializer := v.(Caller)
-		_, err := initializer.Call(args...)
+	ci := ClassInstance{
+		Class:      cc,
+		Properties: make(map[string]interface{}),
+		Private:    make(map[*ClassCaller]map[string]interface{}),
+	}
+
+	if initializer, ok := cc.findMethod("init"); ok {
+		_, err := initializer.bind(&ci).call(args, named)
 		if err != nil {
 			return nil, err
 		}
@@ -661,31 +1750,73 @@ func (cc *ClassCaller) Call(args ...interface{}) (interface{}, error) {
 	return &ci, nil
 }
 
-func (cc *ClassCaller) Arity() int { return cc.arity }
+func (cc *ClassCaller) Arity() (int, int) {
+	if initializer, ok := cc.findMethod("init"); ok {
+		return initializer.Arity()
+	}
+
+	return 0, 0
+}
 
 func (cc *ClassCaller) String() string {
-	return fmt.Sprintf("%s instance", cc.Name)
+	return cc.Name
 }
 
+// FunCaller is a function at runtime. Calling a Generator function does not run its
+// body, it returns a generator running it step by step instead.
 type FunCaller struct {
-	Name   string
-	Params []IdentifierExpr
-	Body   Statement
+	Name      string
+	Params    []Parameter
+	Body      Statement
+	Generator bool
 
 	closure *Environment
 }
 
-func (fc *FunCaller) Call(args ...interface{}) (ret interface{}, err error) {
+func (fc *FunCaller) Call(args ...interface{}) (interface{}, error) {
+	return fc.call(args, nil)
+}
+
+// call runs the function, parameters which are not covered by the positional args are
+// taken from named or else from their default value.
+func (fc *FunCaller) call(args []interface{}, named map[string]interface{}) (ret interface{}, err error) {
 	localEnv := ExpandEnv(fc.closure)
 
-	for i := 0; i < len(fc.Params); i++ {
-		localEnv.SetBinding(fc.Params[i].Name, args[i])
+	for i, param := range fc.Params {
+		val, isNamed := named[param.Name]
+
+		switch {
+		case param.Rest:
+			rest := List{Elements: []interface{}{}}
+			if i < len(args) {
+				rest.Elements = append(rest.Elements, args[i:]...)
+			}
+
+			localEnv.SetBinding(param.Name, &rest)
+		case i < len(args):
+			localEnv.SetBinding(param.Name, args[i])
+		case isNamed:
+			localEnv.SetBinding(param.Name, val)
+		default:
+			// defaults are evaluated in the call's environment so they can refer to
+			// the parameters before them
+			val, err := param.Default.Eval(localEnv)
+			if err != nil {
+				return nil, err
+			}
+
+			localEnv.SetBinding(param.Name, val)
+		}
+	}
+
+	if fc.Generator {
+		return newGenerator(fc, localEnv), nil
 	}
 
 	defer func() {
 		if res := recover(); res != nil {
 			if rv, ok := res.(*ReturnValue); ok {
-				ret = rv
+				ret = rv.Value
 				return
 			}
 
@@ -697,12 +1828,56 @@ func (fc *FunCaller) Call(args ...interface{}) (ret interface{}, err error) {
 	return
 }
 
-func (fc *FunCaller) Arity() int { return len(fc.Params) }
+func addMethods(dest map[string]*FunCaller, decls []*FunDeclStmt, closure *Environment) {
+	for _, m := range decls {
+		dest[m.Name] = &FunCaller{
+			Name:      m.Name,
+			Params:    m.Params,
+			Body:      m.Body,
+			Generator: m.Generator,
+			closure:   closure,
+		}
+	}
+}
+
+// bind returns a copy of the method whose body sees this, an instance or a class for
+// static methods, as "this".
+func (fc *FunCaller) bind(this interface{}) *FunCaller {
+	env := ExpandEnv(fc.closure)
+	env.SetBinding("this", this)
+
+	return &FunCaller{
+		Name:      fc.Name,
+		Params:    fc.Params,
+		Body:      fc.Body,
+		Generator: fc.Generator,
+		closure:   env,
+	}
+}
+
+func (fc *FunCaller) Arity() (int, int) {
+	minArity := 0
+	for _, param := range fc.Params {
+		if param.Default != nil || param.Rest {
+			break
+		}
+
+		minArity++
+	}
+
+	if len(fc.Params) > 0 && fc.Params[len(fc.Params)-1].Rest {
+		return minArity, -1
+	}
+
+	return minArity, len(fc.Params)
+}
 
 func (fc *FunCaller) String() string {
 	return fmt.Sprintf("<fn %s>", fc.Name)
 }
 
+var ErrAbstractClass = errors.New("Cannot instantiate abstract class")
+
 func isTrue(val interface{}) bool {
 	if val == nil {
 		return false
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Generators run the body of a generator function on a goroutine of its own which
+// hands control back and forth with its caller: the body runs until it yields a value
+// and then waits until the next value is asked for. The body runs on behalf of the task
+// asking for the value, which waits for it without the interpreter lock, and takes the
+// lock itself like any other task. Tasks sharing a generator take turns, one asking for
+// a value blocks while the body runs for another one.
+// A generator which is not run to completion leaves its goroutine blocked.
+
+// generatorBinding binds the running generator in the environment of its body, it is
+// not a valid identifier so scripts cannot refer to it.
+const generatorBinding = "<generator>"
+
+type generatorStep struct {
+	val  interface{}
+	done bool
+	err  error
+}
+
+type Generator struct {
+	fun *FunCaller
+	env *Environment
+
+	started bool
+	done    bool
+	resume  chan struct{}
+	steps   chan generatorStep
+
+	// running is set while a task advances the generator, the tasks waiting for their
+	// turn are queued in waiters.
+	running bool
+	waiters []*waiter
+
+	// pending holds the value the body yielded when hasNext() had to run it to find
+	// out whether there is one.
+	pending    interface{}
+	hasPending bool
+}
+
+func newGenerator(fc *FunCaller, env *Environment) *Generator {
+	g := Generator{
+		fun:    fc,
+		env:    env,
+		resume: make(chan struct{}),
+		steps:  make(chan generatorStep),
+	}
+
+	env.SetBinding(generatorBinding, &g)
+
+	return &g
+}
+
+func (g *Generator) run() {
+	interpreterLock.Lock()
+
+	step := generatorStep{done: true}
+
+	defer func() {
+		if res := recover(); res != nil {
+			if _, ok := res.(*ReturnValue); !ok {
+				panic(res)
+			}
+		}
+
+		interpreterLock.Unlock()
+		g.steps <- step
+	}()
+
+	_, step.err = g.fun.Body.Execute(g.env)
+}
+
+// yield is called from the body, it hands val to the caller and blocks until the next
+// value is asked for.
+func (g *Generator) yield(val interface{}) {
+	blocking(func() {
+		g.steps <- generatorStep{val: val}
+		<-g.resume
+	})
+}
+
+// lock waits until no other task advances the generator.
+func (g *Generator) lock() error {
+	if !g.running {
+		g.running = true
+		return nil
+	}
+
+	w := newWaiter()
+	g.waiters = append(g.waiters, w)
+
+	return w.block()
+}
+
+// unlock hands the generator over to the next task waiting for it.
+func (g *Generator) unlock() {
+	for len(g.waiters) > 0 {
+		w := g.waiters[0]
+		g.waiters = g.waiters[1:]
+
+		if !w.done {
+			w.complete(0, nil, true, nil)
+			return
+		}
+	}
+
+	g.running = false
+}
+
+// advance runs the body up to its next yield, it returns false once the body is done.
+// The generator must be locked.
+func (g *Generator) advance() (bool, error) {
+	if g.hasPending {
+		return true, nil
+	}
+
+	if g.done {
+		return false, nil
+	}
+
+	resume := g.started
+	g.started = true
+
+	var step generatorStep
+
+	blocking(func() {
+		if resume {
+			g.resume <- struct{}{}
+		} else {
+			go g.run()
+		}
+
+		step = <-g.steps
+	})
+
+	if step.err != nil || step.done {
+		g.done = true
+		return false, step.err
+	}
+
+	g.pending, g.hasPending = step.val, true
+
+	return true, nil
+}
+
+func (g *Generator) hasNext() (bool, error) {
+	err := g.lock()
+	if err != nil {
+		return false, err
+	}
+	defer g.unlock()
+
+	return g.advance()
+}
+
+// take returns the next value, the second return value is false once the body is
+// done. Unlike calling hasNext() and then next(), no other task can take the value in
+// between.
+func (g *Generator) take() (interface{}, bool, error) {
+	err := g.lock()
+	if err != nil {
+		return nil, false, err
+	}
+	defer g.unlock()
+
+	ok, err := g.advance()
+	if err != nil || !ok {
+		return nil, false, err
+	}
+
+	val := g.pending
+	g.pending, g.hasPending = nil, false
+
+	return val, true, nil
+}
+
+func (g *Generator) next() (interface{}, error) {
+	val, ok, err := g.take()
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return nil, fmt.Errorf("Generator %s has no more values.", g.fun.Name)
+	}
+
+	return val, nil
+}
+
+// method returns the method called name of the generator, bound to it.
+func (g *Generator) method(name string) (Caller, bool) {
+	switch name {
+	case "next":
+		return &BuiltinMethod{call: func(_ ...interface{}) (interface{}, error) { return g.next() }}, true
+	case "hasNext":
+		return &BuiltinMethod{call: func(_ ...interface{}) (interface{}, error) { return g.hasNext() }}, true
+	}
+
+	return nil, false
+}
+
+func (g *Generator) String() string {
+	return fmt.Sprintf("<generator %s>", g.fun.Name)
+}
+
+type YieldStmt struct {
+	Expr Expression
+}
+
+func (ys *YieldStmt) Execute(env *Environment) (interface{}, error) {
+	val, err := ys.Expr.Eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	genEnv, ok := env.Lookup(generatorBinding)
+	if !ok {
+		// unreachable, the parser only accepts yield inside generator functions
+		return nil, errors.New("Can't yield outside of a generator.")
+	}
+
+	genEnv.Bindings[generatorBinding].(*Generator).yield(val)
+
+	return nil, nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import "testing"
+
+func TestGenerators(t *testing.T) {
+	runProgramTests(t, []programTest{
+		{
+			name:   "next and hasNext",
+			source: "fun* g() { yield 1; yield 2; }\nvar it = g();\nprint it.next();\nprint it.hasNext();\nprint it.next();\nprint it.hasNext();",
+			want:   "1\ntrue\n2\nfalse\n",
+		},
+		{
+			name:    "next after the last value",
+			source:  "fun* g() { yield 1; }\nvar it = g();\nit.next();\nit.next();",
+			wantErr: "Generator g has no more values.\n[line 4]",
+		},
+		{
+			name:   "for-in",
+			source: "fun* squares(n) { for (var i in range(n)) yield i * i; }\nfor (var x in squares(4)) print x;",
+			want:   "0\n1\n4\n9\n",
+		},
+		{
+			name:   "the body runs lazily",
+			source: "fun* g() { print \"started\"; yield 1; }\nvar it = g();\nprint \"created\";\nprint it.next();",
+			want:   "created\nstarted\n1\n",
+		},
+		{
+			name:   "return ends the generator",
+			source: "fun* g() { yield 1; return; yield 2; }\nfor (var x in g()) print x;",
+			want:   "1\n",
+		},
+		{
+			name:   "yield without a value",
+			source: "fun* g() { yield; }\nprint g().next();",
+			want:   "nil\n",
+		},
+		{
+			name:   "generator method",
+			source: "class A {\n  init() { this.x = 5; }\n  *items() { yield this.x; }\n}\nfor (var v in A().items()) print v;",
+			want:   "5\n",
+		},
+		{
+			name:   "independent generators",
+			source: "fun* count() { var i = 0; while (true) { yield i; i = i + 1; } }\nvar a = count();\nvar b = count();\na.next();\nprint a.next();\nprint b.next();",
+			want:   "1\n0\n",
+		},
+		{
+			name:   "printing a generator",
+			source: "fun* g() { yield 1; }\nprint g();",
+			want:   "<generator g>\n",
+		},
+		{
+			name:    "error in the body",
+			source:  "fun* g() {\n  yield nil + 1;\n}\nfor (var x in g()) print x;",
+			wantErr: "Operands must be two numbers or two strings.\n[line 2]",
+		},
+		{
+			name:    "yield outside of a function",
+			source:  "yield 1;",
+			wantErr: "[line 1] Error at 'yield': Can't yield outside of a generator.",
+		},
+		{
+			name:    "yield in a function which is not a generator",
+			source:  "fun f() { yield 1; }",
+			wantErr: "[line 1] Error at 'yield': Can't yield outside of a generator.",
+		},
+	})
+}
@@ -7,12 +7,30 @@ import (
 )
 
 type Environment struct {
-	Bindings map[string]interface{}
-	parent   *Environment
+	Bindings  map[string]interface{}
+	parent    *Environment
+	constants map[string]bool
 }
 
+// SetBinding binds name to value, a new declaration of a constant's name makes it a
+// variable again.
 func (e *Environment) SetBinding(name string, value interface{}) {
 	e.Bindings[name] = value
+	delete(e.constants, name)
+}
+
+func (e *Environment) SetConstant(name string, value interface{}) {
+	e.Bindings[name] = value
+
+	if e.constants == nil {
+		e.constants = make(map[string]bool)
+	}
+
+	e.constants[name] = true
+}
+
+func (e *Environment) IsConstant(name string) bool {
+	return e.constants[name]
 }
 
 func (e *Environment) Lookup(name string) (*Environment, bool) {
@@ -40,7 +58,23 @@ func NewInterpreter() *Interpreter {
 	return &Interpreter{
 		env: Environment{
 			Bindings: map[string]interface{}{
-				"clock": &NativeClock{},
+				"clock":    &NativeClock{},
+				"BigInt":   &NativeBigInt{},
+				"Decimal":  &NativeDecimal{},
+				"Number":   &NativeNumber{},
+				"str":      &NativeStr{},
+				"hash":     &NativeHash{},
+				"len":      &NativeLen{},
+				"range":    &NativeRange{},
+				"Channel":  &NativeChannel{},
+				"sleep":    &NativeSleep{},
+				"typeof":   &NativeTypeOf{},
+				"fields":   &NativeFields{},
+				"methods":  &NativeMethods{},
+				"hasField": &NativeHasField{},
+				"getField": &NativeGetField{},
+				"setField": &NativeSetField{},
+				"freeze":   &NativeFreeze{},
 			},
 		},
 	}
@@ -61,6 +95,9 @@ func (i *Interpreter) Interpret(content []byte) error {
 
 	parser := NewParser(tokens)
 
+	interpreterLock.Lock()
+	defer interpreterLock.Unlock()
+
 	for stmt, err := parser.NextDeclaration(); !errors.Is(err, ErrNoMoreTokens); stmt, err = parser.NextDeclaration() {
 		if err != nil {
 			_, _ = fmt.Fprintf(os.Stderr, err.Error())
@@ -74,5 +111,11 @@ func (i *Interpreter) Interpret(content []byte) error {
 		}
 	}
 
+	err := waitForTasks()
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, err.Error())
+		os.Exit(70)
+	}
+
 	return nil
 }
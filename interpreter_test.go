@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"testing"
+)
+
+// runProgram interprets source like the run command does and returns what it printed
+// along with the error it would have exited with.
+func runProgram(t *testing.T, source string) (string, error) {
+	t.Helper()
+
+	scanner := NewScanner([]byte(source))
+
+	var tokens []*Token
+	for scanner.HasNext() {
+		token, err := scanner.NextToken()
+		if err != nil {
+			return "", err
+		}
+
+		tokens = append(tokens, token)
+	}
+
+	var stmts []Statement
+
+	parser := NewParser(tokens)
+	for stmt, err := parser.NextDeclaration(); !errors.Is(err, ErrNoMoreTokens); stmt, err = parser.NextDeclaration() {
+		if err != nil {
+			return "", err
+		}
+
+		stmts = append(stmts, stmt)
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stdout := os.Stdout
+	os.Stdout = w
+
+	output := make(chan string)
+	go func() {
+		var buf bytes.Buffer
+		_, _ = io.Copy(&buf, r)
+		output <- buf.String()
+	}()
+
+	interpreterLock.Lock()
+
+	env := NewInterpreter().env
+
+	for _, stmt := range stmts {
+		_, err = stmt.Execute(&env)
+		if err != nil {
+			break
+		}
+	}
+
+	if err == nil {
+		err = waitForTasks()
+	}
+
+	// wait for the tasks a failed program left running, those blocked for good are
+	// abandoned like when the process exits
+	for _, task := range scheduler.tasks {
+		_, _ = task.wait()
+	}
+
+	scheduler.live, scheduler.blocked = 1, 0
+	scheduler.waiting = map[*waiter]struct{}{}
+	scheduler.tasks = nil
+
+	interpreterLock.Unlock()
+
+	os.Stdout = stdout
+	_ = w.Close()
+
+	return <-output, err
+}
+
+type programTest struct {
+	name    string
+	source  string
+	want    string
+	wantErr string
+}
+
+func runProgramTests(t *testing.T, tests []programTest) {
+	t.Helper()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := runProgram(t, tt.source)
+
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("got error %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got != tt.want {
+				t.Fatalf("got output %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
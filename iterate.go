@@ -0,0 +1,253 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// For-in loops iterate over lists, the characters of strings, ranges, generators,
+// channels until they are closed and instances of classes implementing the iterator
+// protocol: an iterator() method returning an object whose hasNext() method tells
+// whether next() has another value to return. Generators implement hasNext() and next()
+// themselves.
+
+// Iterator returns the next value of an iteration, the second return value is false
+// once it is exhausted.
+type Iterator func() (interface{}, bool, error)
+
+func iterate(val interface{}) (Iterator, error) {
+	switch v := val.(type) {
+	case *List:
+		// the length is checked on every step so elements appended while iterating
+		// are visited as well
+		i := 0
+
+		return func() (interface{}, bool, error) {
+			if i >= len(v.Elements) {
+				return nil, false, nil
+			}
+
+			i++
+
+			return v.Elements[i-1], true, nil
+		}, nil
+	case string:
+		i := 0
+
+		return func() (interface{}, bool, error) {
+			if i >= len(v) {
+				return nil, false, nil
+			}
+
+			i++
+
+			return v[i-1 : i], true, nil
+		}, nil
+	case *Range:
+		curr, done := v.Start, false
+
+		return func() (interface{}, bool, error) {
+			if done || !v.contains(curr) {
+				return nil, false, nil
+			}
+
+			val := curr
+
+			// the range ends early rather than wrapping around when the next value
+			// does not fit in an int64
+			next := curr + v.Step
+			if (v.Step > 0 && next < curr) || (v.Step < 0 && next > curr) {
+				done = true
+			}
+
+			curr = next
+
+			return val, true, nil
+		}, nil
+	case *Generator:
+		return v.take, nil
+	case *Channel:
+		return func() (interface{}, bool, error) {
+			return v.recv()
+		}, nil
+	case *ClassInstance:
+		return iterateInstance(v)
+	}
+
+	return nil, fmt.Errorf("Cannot iterate over %s.", typeName(val))
+}
+
+func iterateInstance(ci *ClassInstance) (Iterator, error) {
+	m, ok := ci.findMethod("iterator")
+	if !ok {
+		return nil, fmt.Errorf("Cannot iterate over %s instance, it has no iterator() method.", ci.Class.Name)
+	}
+
+	it, err := m.(Caller).Call()
+	if err != nil {
+		return nil, err
+	}
+
+	iterator, ok := it.(*ClassInstance)
+	if !ok {
+		return nil, errors.New("iterator() must return an object with hasNext() and next() methods.")
+	}
+
+	hasNext, ok := iterator.findMethod("hasNext")
+	next, ok2 := iterator.findMethod("next")
+	if !ok || !ok2 {
+		return nil, fmt.Errorf("Iterator %s must have hasNext() and next() methods.", iterator.Class.Name)
+	}
+
+	return func() (interface{}, bool, error) {
+		more, err := hasNext.(Caller).Call()
+		if err != nil || !isTrue(more) {
+			return nil, false, err
+		}
+
+		val, err := next.(Caller).Call()
+		if err != nil {
+			return nil, false, err
+		}
+
+		return val, true, nil
+	}, nil
+}
+
+type ForInStmt struct {
+	Target   Expression
+	Iterable Expression
+	Body     Statement
+	Line     int
+}
+
+func (f *ForInStmt) Execute(env *Environment) (interface{}, error) {
+	val, err := f.Iterable.Eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	next, err := iterate(val)
+	if err != nil {
+		return nil, fmt.Errorf("%w\n[line %d]", err, f.Line)
+	}
+
+	for {
+		val, ok, err := next()
+		if err == errDeadlock {
+			// blocked receiving from a channel, other errors come with their line
+			return nil, fmt.Errorf("%w\n[line %d]", err, f.Line)
+		}
+
+		if err != nil || !ok {
+			return nil, err
+		}
+
+		// every iteration gets its own variable, closures created in the body keep
+		// the value of their iteration
+		loopEnv := ExpandEnv(env)
+
+		err = destructure(loopEnv, f.Target, val, f.Line, func(target Expression, val interface{}) error {
+			loopEnv.SetBinding(target.(*IdentifierExpr).Name, val)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = f.Body.Execute(loopEnv)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Range is the sequence of integers from Start up to, but excluding, End, counting by
+// Step which may be negative.
+type Range struct {
+	Start int64
+	End   int64
+	Step  int64
+}
+
+func (r *Range) contains(i int64) bool {
+	if r.Step > 0 {
+		return i < r.End
+	}
+
+	return i > r.End
+}
+
+// length is the number of integers in the range, computed with big integers since the
+// distance between the bounds may not fit in an int64. Like integer arithmetic, it
+// falls back to a float when the result does not either.
+func (r *Range) length() interface{} {
+	if !r.contains(r.Start) {
+		return int64(0)
+	}
+
+	span := new(big.Int).Sub(big.NewInt(r.End), big.NewInt(r.Start))
+	step := big.NewInt(r.Step)
+
+	if r.Step < 0 {
+		span.Neg(span)
+		step.Neg(step)
+	}
+
+	// the number of steps rounded up
+	n := span.Add(span, step)
+	n.Sub(n, big.NewInt(1)).Quo(n, step)
+
+	if n.IsInt64() {
+		return n.Int64()
+	}
+
+	f, _ := new(big.Float).SetInt(n).Float64()
+
+	return f
+}
+
+func (r *Range) String() string {
+	return fmt.Sprintf("range(%d, %d, %d)", r.Start, r.End, r.Step)
+}
+
+// NativeRange creates ranges: range(end), range(start, end) and range(start, end,
+// step).
+type NativeRange struct{}
+
+func (nr *NativeRange) Call(args ...interface{}) (interface{}, error) {
+	bounds := make([]int64, 0, len(args))
+
+	for _, arg := range args {
+		i, ok := arg.(int64)
+		if !ok {
+			return nil, errors.New("range() expects integers.")
+		}
+
+		bounds = append(bounds, i)
+	}
+
+	r := Range{Step: 1}
+
+	switch len(bounds) {
+	case 1:
+		r.End = bounds[0]
+	case 2:
+		r.Start, r.End = bounds[0], bounds[1]
+	case 3:
+		r.Start, r.End, r.Step = bounds[0], bounds[1], bounds[2]
+	}
+
+	if r.Step == 0 {
+		return nil, errors.New("range() step cannot be zero.")
+	}
+
+	return &r, nil
+}
+
+func (nr *NativeRange) Arity() (int, int) { return 1, 3 }
+
+func (nr *NativeRange) String() string {
+	return "<native fn>"
+}
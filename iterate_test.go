@@ -0,0 +1,161 @@
+package main
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestIterateRange(t *testing.T) {
+	tests := []struct {
+		name string
+		r    Range
+		want []interface{}
+	}{
+		{"ascending", Range{Start: 0, End: 3, Step: 1}, []interface{}{int64(0), int64(1), int64(2)}},
+		{"step", Range{Start: 1, End: 8, Step: 3}, []interface{}{int64(1), int64(4), int64(7)}},
+		{"descending", Range{Start: 3, End: 0, Step: -1}, []interface{}{int64(3), int64(2), int64(1)}},
+		{"empty", Range{Start: 3, End: 3, Step: 1}, nil},
+		{"wrong direction", Range{Start: 0, End: 3, Step: -1}, nil},
+		{
+			"up to MaxInt64",
+			Range{Start: math.MaxInt64 - 2, End: math.MaxInt64, Step: 1},
+			[]interface{}{int64(math.MaxInt64 - 2), int64(math.MaxInt64 - 1)},
+		},
+		{
+			"step past MaxInt64",
+			Range{Start: math.MaxInt64 - 5, End: math.MaxInt64, Step: 4},
+			[]interface{}{int64(math.MaxInt64 - 5), int64(math.MaxInt64 - 1)},
+		},
+		{
+			"step past MinInt64",
+			Range{Start: math.MinInt64 + 5, End: math.MinInt64, Step: -4},
+			[]interface{}{int64(math.MinInt64 + 5), int64(math.MinInt64 + 1)},
+		},
+		{
+			"largest step",
+			Range{Start: 0, End: math.MaxInt64, Step: math.MaxInt64},
+			[]interface{}{int64(0)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next, err := iterate(&tt.r)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var got []interface{}
+
+			// more values than expected means the range wrapped around
+			for len(got) <= len(tt.want) {
+				val, ok, err := next()
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+
+				if !ok {
+					break
+				}
+
+				got = append(got, val)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRangeLength(t *testing.T) {
+	tests := []struct {
+		name string
+		r    Range
+		want interface{}
+	}{
+		{"ascending", Range{Start: 0, End: 10, Step: 1}, int64(10)},
+		{"rounded up", Range{Start: 0, End: 10, Step: 3}, int64(4)},
+		{"descending", Range{Start: 10, End: 0, Step: -3}, int64(4)},
+		{"empty", Range{Start: 5, End: 5, Step: 1}, int64(0)},
+		{"wrong direction", Range{Start: 0, End: 5, Step: -1}, int64(0)},
+		{"up to MaxInt64", Range{Start: 0, End: math.MaxInt64, Step: 1}, int64(math.MaxInt64)},
+		{"wider than int64 with a step", Range{Start: math.MinInt64, End: math.MaxInt64, Step: 4}, int64(1 << 62)},
+		{"just wider than int64", Range{Start: math.MinInt64, End: math.MaxInt64, Step: 2}, 9223372036854775808.0},
+		{"wider than int64", Range{Start: math.MinInt64, End: math.MaxInt64, Step: 1}, 18446744073709551615.0},
+		{"wider than int64 descending", Range{Start: math.MaxInt64, End: math.MinInt64, Step: -1}, 18446744073709551615.0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.r.length()
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestForIn(t *testing.T) {
+	runProgramTests(t, []programTest{
+		{
+			name:   "list",
+			source: "for (var x in [1, 2, 3]) print x;",
+			want:   "1\n2\n3\n",
+		},
+		{
+			name:   "string",
+			source: `for (var c in "abc") print c;`,
+			want:   "a\nb\nc\n",
+		},
+		{
+			name:   "range",
+			source: "for (var i in range(1, 10, 4)) print i;\nprint len(range(1, 10, 4));",
+			want:   "1\n5\n9\n3\n",
+		},
+		{
+			name:   "destructuring target",
+			source: "for (var [a, b] in [[1, 2], [3, 4]]) print a + b;",
+			want:   "3\n7\n",
+		},
+		{
+			name:   "closures keep the value of their iteration",
+			source: "var fs = [];\nfor (var i in range(3)) {\n  fun f() { return i; }\n  fs = [...fs, f];\n}\nfor (var f in fs) print f();",
+			want:   "0\n1\n2\n",
+		},
+		{
+			name: "iterator protocol",
+			source: `
+class Countdown {
+  init(n) { this.n = n; }
+  iterator() { return this; }
+  hasNext() { return this.n > 0; }
+  next() { this.n = this.n - 1; return this.n + 1; }
+}
+for (var i in Countdown(3)) print i;
+`,
+			want: "3\n2\n1\n",
+		},
+		{
+			name:    "not iterable",
+			source:  "for (var x in 1) print x;",
+			wantErr: "Cannot iterate over number.\n[line 1]",
+		},
+		{
+			name:    "instance without iterator()",
+			source:  "class A {}\nfor (var x in A()) print x;",
+			wantErr: "Cannot iterate over A instance, it has no iterator() method.\n[line 2]",
+		},
+		{
+			name:    "range of floats",
+			source:  "range(1.5);",
+			wantErr: "range() expects integers.\n[line 1]",
+		},
+		{
+			name:    "range with a zero step",
+			source:  "range(0, 1, 0);",
+			wantErr: "range() step cannot be zero.\n[line 1]",
+		},
+	})
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// List is the runtime value of list literals like [1, 2, 3].
+type List struct {
+	Elements []interface{}
+}
+
+func (l *List) String() string {
+	s, _ := Stringify(l)
+	return s
+}
+
+type ListExpr struct {
+	Elements []Expression
+	Line     int
+}
+
+func (le *ListExpr) Eval(env *Environment) (interface{}, error) {
+	elements, err := evalArguments(env, le.Elements)
+	if err != nil {
+		return nil, err
+	}
+
+	return &List{Elements: elements}, nil
+}
+
+// SpreadExpr is a "...list" argument or list element, it stands for the elements of
+// the list.
+type SpreadExpr struct {
+	Expr Expression
+	Line int
+}
+
+func (se *SpreadExpr) Eval(_ *Environment) (interface{}, error) {
+	return nil, fmt.Errorf("Spread is only allowed in argument lists and list literals.\n[line %d]", se.Line)
+}
+
+func (se *SpreadExpr) String() string {
+	return fmt.Sprintf("(... %v)", se.Expr)
+}
+
+// evalArguments evaluates a list of expressions which may contain spreads.
+func evalArguments(env *Environment, exprs []Expression) ([]interface{}, error) {
+	values := make([]interface{}, 0, len(exprs))
+
+	for _, e := range exprs {
+		spread, isSpread := e.(*SpreadExpr)
+		if isSpread {
+			e = spread.Expr
+		}
+
+		v, err := e.Eval(env)
+		if err != nil {
+			return nil, err
+		}
+
+		if !isSpread {
+			values = append(values, v)
+			continue
+		}
+
+		l, ok := v.(*List)
+		if !ok {
+			return nil, fmt.Errorf("Only lists can be spread.\n[line %d]", spread.Line)
+		}
+
+		values = append(values, l.Elements...)
+	}
+
+	return values, nil
+}
+
+func (le *ListExpr) String() string {
+	var sb strings.Builder
+
+	sb.WriteString("(list")
+
+	for _, e := range le.Elements {
+		sb.WriteString(fmt.Sprintf(" %v", e))
+	}
+
+	sb.WriteString(")")
+
+	return sb.String()
+}
+
+// index returns the position index refers to in a sequence of length elements, or false
+// if it is not an integer in range.
+func index(val interface{}, length int) (int, bool) {
+	i, ok := val.(int64)
+	if !ok || i < 0 || i >= int64(length) {
+		return 0, false
+	}
+
+	return int(i), true
+}
+
+// NativeLen returns the length of a list or a string.
+type NativeLen struct{}
+
+func (nl *NativeLen) Call(args ...interface{}) (interface{}, error) {
+	switch v := args[0].(type) {
+	case *List:
+		return int64(len(v.Elements)), nil
+	case string:
+		return int64(len(v)), nil
+	case *Range:
+		return v.length(), nil
+	}
+
+	return nil, fmt.Errorf("len() expects a list, a string or a range.")
+}
+
+func (nl *NativeLen) Arity() (int, int) { return 1, 1 }
+
+func (nl *NativeLen) String() string {
+	return "<native fn>"
+}
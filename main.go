@@ -90,7 +90,14 @@ func main() {
 					os.Exit(70)
 				}
 
-				fmt.Println(strHelper(v))
+				s, err := Stringify(v)
+				if err != nil {
+					errFound = true
+					fmt.Fprintln(os.Stderr, err.Error()+"\n")
+					os.Exit(70)
+				}
+
+				fmt.Println(s)
 			}
 		}
 
@@ -98,14 +105,19 @@ func main() {
 			os.Exit(65)
 		}
 	} else if command == "run" {
-		_ = NewInterpreter().Interpret(fileContents)
-	}
-}
+		err := NewInterpreter().Interpret(fileContents)
+		if err != nil {
+			fmt.Fprint(os.Stderr, err.Error()+"\n")
+			os.Exit(65)
+		}
+	} else if command == "check" {
+		errs := CheckProgram(fileContents)
+		for _, err := range errs {
+			fmt.Fprintln(os.Stderr, err.Error())
+		}
 
-func strHelper(v interface{}) string {
-	if v == nil {
-		return "nil"
+		if len(errs) > 0 {
+			os.Exit(65)
+		}
 	}
-
-	return fmt.Sprintf("%v", v)
 }
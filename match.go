@@ -0,0 +1,257 @@
+package main
+
+import "fmt"
+
+// Match statements and expressions compare a value against the patterns of their
+// cases in order and run the first case whose pattern matches and whose guard, if any,
+// is true. Names bound by the pattern are visible in the guard and in the body of the
+// case only.
+
+type MatchCase struct {
+	Patterns []Pattern
+	Guard    Expression
+
+	// Body is set for match statements and Expr for match expressions.
+	Body Statement
+	Expr Expression
+}
+
+// matchCase returns the first case matching val along with the environment holding the
+// names its pattern binds, or nil when no case matches.
+func matchCase(env *Environment, val interface{}, cases []*MatchCase) (*MatchCase, *Environment, error) {
+	for _, c := range cases {
+		for _, pattern := range c.Patterns {
+			caseEnv := ExpandEnv(env)
+
+			ok, err := pattern.Match(caseEnv, val)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			if !ok {
+				continue
+			}
+
+			if c.Guard != nil {
+				guard, err := c.Guard.Eval(caseEnv)
+				if err != nil {
+					return nil, nil, err
+				}
+
+				if !isTrue(guard) {
+					continue
+				}
+			}
+
+			return c, caseEnv, nil
+		}
+	}
+
+	return nil, nil, nil
+}
+
+type MatchStmt struct {
+	Subject Expression
+	Cases   []*MatchCase
+}
+
+func (ms *MatchStmt) Execute(env *Environment) (interface{}, error) {
+	val, err := ms.Subject.Eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	c, caseEnv, err := matchCase(env, val, ms.Cases)
+	if err != nil || c == nil {
+		return nil, err
+	}
+
+	return c.Body.Execute(caseEnv)
+}
+
+// MatchExpr evaluates to the expression of the matching case, a value no case matches
+// is an error.
+type MatchExpr struct {
+	Subject Expression
+	Cases   []*MatchCase
+	Line    int
+}
+
+func (me *MatchExpr) Eval(env *Environment) (interface{}, error) {
+	val, err := me.Subject.Eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	c, caseEnv, err := matchCase(env, val, me.Cases)
+	if err != nil {
+		return nil, err
+	}
+
+	if c == nil {
+		s, err := stringifyAt(val, me.Line)
+		if err != nil {
+			return nil, err
+		}
+
+		return nil, fmt.Errorf("No case matches %s.\n[line %d]", s, me.Line)
+	}
+
+	return c.Expr.Eval(caseEnv)
+}
+
+func (me *MatchExpr) String() string {
+	return fmt.Sprintf("(match %v)", me.Subject)
+}
+
+type Pattern interface {
+	// Match reports whether val matches the pattern, binding the names the pattern
+	// captures in env.
+	Match(env *Environment, val interface{}) (bool, error)
+}
+
+// WildcardPattern is "_", it matches anything.
+type WildcardPattern struct{}
+
+func (wp *WildcardPattern) Match(_ *Environment, _ interface{}) (bool, error) {
+	return true, nil
+}
+
+// BindingPattern is a name, it matches anything and binds it to the value.
+type BindingPattern struct {
+	Name string
+}
+
+func (bp *BindingPattern) Match(env *Environment, val interface{}) (bool, error) {
+	env.SetBinding(bp.Name, val)
+
+	return true, nil
+}
+
+// ValuePattern matches values equal to a literal or to a constant referred to by a
+// dotted name like Color.Red.
+type ValuePattern struct {
+	Expr Expression
+}
+
+func (vp *ValuePattern) Match(env *Environment, val interface{}) (bool, error) {
+	expected, err := vp.Expr.Eval(env)
+	if err != nil {
+		return false, err
+	}
+
+	return valuesEqual(val, expected)
+}
+
+// ListPattern matches lists element by element. Without a Rest pattern the list must
+// have exactly as many elements as the pattern, the Rest pattern is matched against a
+// list of the remaining elements.
+type ListPattern struct {
+	Elements []Pattern
+	Rest     Pattern
+}
+
+func (lp *ListPattern) Match(env *Environment, val interface{}) (bool, error) {
+	l, ok := val.(*List)
+	if !ok {
+		return false, nil
+	}
+
+	if len(l.Elements) < len(lp.Elements) || (lp.Rest == nil && len(l.Elements) != len(lp.Elements)) {
+		return false, nil
+	}
+
+	for i, p := range lp.Elements {
+		ok, err := p.Match(env, l.Elements[i])
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+
+	if lp.Rest == nil {
+		return true, nil
+	}
+
+	rest := List{Elements: append([]interface{}{}, l.Elements[len(lp.Elements):]...)}
+
+	return lp.Rest.Match(env, &rest)
+}
+
+// ClassPattern matches instances of a class, trait or interface. Positional Args are
+// matched against the fields named like the parameters of the class's init method,
+// Named ones against the field they name. An instance lacking one of the fields does
+// not match.
+type ClassPattern struct {
+	Class *IdentifierExpr
+	Args  []Pattern
+	Named []FieldPattern
+	Line  int
+}
+
+type FieldPattern struct {
+	Name    string
+	Pattern Pattern
+}
+
+func (cp *ClassPattern) Match(env *Environment, val interface{}) (bool, error) {
+	class, err := cp.Class.Eval(env)
+	if err != nil {
+		return false, err
+	}
+
+	switch class.(type) {
+	case *ClassCaller, *Trait, *Interface:
+	default:
+		return false, fmt.Errorf("Pattern %s must be a class, a trait or an interface.\n[line %d]", cp.Class.Name, cp.Line)
+	}
+
+	if ok, _ := isInstance(val, class); !ok {
+		return false, nil
+	}
+
+	var fields []FieldPattern
+
+	if len(cp.Args) > 0 {
+		cc, ok := class.(*ClassCaller)
+		if !ok {
+			return false, fmt.Errorf("Pattern %s must be a class to match positional fields.\n[line %d]", cp.Class.Name, cp.Line)
+		}
+
+		var params []Parameter
+		if initializer, ok := cc.findMethod("init"); ok {
+			params = initializer.Params
+		}
+
+		if len(cp.Args) > len(params) {
+			return false, fmt.Errorf("Pattern %s has %s but init of class %s has %s.\n[line %d]", cp.Class.Name, plural(len(cp.Args), "field"), cc.Name, plural(len(params), "parameter"), cp.Line)
+		}
+
+		for i, arg := range cp.Args {
+			fields = append(fields, FieldPattern{Name: params[i].Name, Pattern: arg})
+		}
+	}
+
+	fields = append(fields, cp.Named...)
+
+	ci := val.(*ClassInstance)
+
+	for _, f := range fields {
+		_, isField := ci.Properties[f.Name]
+		_, hasGetter := ci.Class.findGetter(f.Name)
+		if !isField && !hasGetter {
+			return false, nil
+		}
+
+		fieldVal, err := getProperty(env, ci, f.Name, cp.Line)
+		if err != nil {
+			return false, err
+		}
+
+		ok, err := f.Pattern.Match(env, fieldVal)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
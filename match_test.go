@@ -0,0 +1,100 @@
+package main
+
+import "testing"
+
+func TestMatch(t *testing.T) {
+	describe := `
+fun describe(v) {
+  return match (v) {
+    case nil => "nil";
+    case 0 => "zero";
+    case -1 => "minus one";
+    case "hi", "hello" => "greeting";
+    case true => "yes";
+    case [] => "empty";
+    case [x] => "one " + str(x);
+    case [x, y] if x == y => "pair of " + str(x);
+    case [a, [b, c]] => "nested " + str(a + b + c);
+    case [first, ...rest] => str(first) + " then " + str(len(rest));
+    case n if n > 100 => "big";
+    case _ => "other";
+  };
+}
+`
+
+	runProgramTests(t, []programTest{
+		{name: "nil literal", source: describe + "print describe(nil);", want: "nil\n"},
+		{name: "number literal", source: describe + "print describe(0);", want: "zero\n"},
+		{name: "number literals match any kind", source: describe + "print describe(0.0);", want: "zero\n"},
+		{name: "negative number literal", source: describe + "print describe(-1);", want: "minus one\n"},
+		{name: "alternative patterns", source: describe + `print describe("hello");`, want: "greeting\n"},
+		{name: "bool literal", source: describe + "print describe(true);", want: "yes\n"},
+		{name: "empty list", source: describe + "print describe([]);", want: "empty\n"},
+		{name: "list binding an element", source: describe + "print describe([7]);", want: "one 7\n"},
+		{name: "guard", source: describe + "print describe([2, 2]);", want: "pair of 2\n"},
+		{name: "failed guard falls through", source: describe + "print describe([2, 3]);", want: "2 then 1\n"},
+		{name: "nested list", source: describe + "print describe([1, [2, 3]]);", want: "nested 6\n"},
+		{name: "rest of a list", source: describe + "print describe([1, 2, 3]);", want: "1 then 2\n"},
+		{name: "binding with a guard", source: describe + "print describe(500);", want: "big\n"},
+		{name: "wildcard", source: describe + "print describe(5);", want: "other\n"},
+		{
+			name: "class patterns",
+			source: `
+class Point { init(x, y) { this.x = x; this.y = y; } }
+fun show(v) {
+  match (v) {
+    case Point(0, 0) => print "origin";
+    case Point(x, y: 0) => print "on the x axis at " + str(x);
+    case Point(x, y) => print str(x) + "," + str(y);
+    case _ => print "not a point";
+  }
+}
+show(Point(0, 0));
+show(Point(3, 0));
+show(Point(1, 2));
+show(1);
+`,
+			want: "origin\non the x axis at 3\n1,2\nnot a point\n",
+		},
+		{
+			name: "enum value patterns",
+			source: `
+enum Color { Red, Green }
+fun show(c) {
+  match (c) {
+    case Color.Red => print "red";
+    case _ => print "not red";
+  }
+}
+show(Color.Red);
+show(Color.Green);
+`,
+			want: "red\nnot red\n",
+		},
+		{
+			name:   "bindings are local to the case",
+			source: "var x = 1;\nmatch (2) { case x => print x; }\nprint x;",
+			want:   "2\n1\n",
+		},
+		{
+			name:   "statement without a matching case",
+			source: "match (9) { case 1 => print 1; }\nprint \"done\";",
+			want:   "done\n",
+		},
+		{
+			name:    "expression without a matching case",
+			source:  "print match (1) {\n  case 2 => \"two\";\n};",
+			wantErr: "No case matches 1.\n[line 1]",
+		},
+		{
+			name:    "class pattern of a value which is not a class",
+			source:  "var n = 1;\nmatch (n) { case n(x) => print x; }",
+			wantErr: "Pattern n must be a class, a trait or an interface.\n[line 2]",
+		},
+		{
+			name:    "class pattern with too many fields",
+			source:  "class P { init(x) { this.x = x; } }\nmatch (P(1)) { case P(a, b) => print a; }",
+			wantErr: "Pattern P has 2 fields but init of class P has 1 parameter.\n[line 2]",
+		},
+	})
+}
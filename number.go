@@ -0,0 +1,213 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// Numbers come in two kinds at runtime: int64 for integral literals and results of
+// integer arithmetic, and float64 for everything else. Integer operations that would
+// overflow are promoted to float64 instead of wrapping around.
+
+func isNumber(val interface{}) bool {
+	switch val.(type) {
+	case int64, float64:
+		return true
+	}
+
+	return false
+}
+
+func toFloat(val interface{}) float64 {
+	switch v := val.(type) {
+	case int64:
+		return float64(v)
+	case float64:
+		return v
+	}
+
+	return math.NaN()
+}
+
+// numberArithmetic applies one of the arithmetic operators to two numbers. Both operands
+// must have passed isNumber.
+func numberArithmetic(operator TokenType, leftVal, rightVal interface{}) interface{} {
+	lv, ok := leftVal.(int64)
+	rv, ok2 := rightVal.(int64)
+	if ok && ok2 {
+		if res, ok := intArithmetic(operator, lv, rv); ok {
+			return res
+		}
+	}
+
+	lf, rf := toFloat(leftVal), toFloat(rightVal)
+
+	switch operator {
+	case PLUS:
+		return lf + rf
+	case MINUS:
+		return lf - rf
+	case STAR:
+		return lf * rf
+	case SLASH:
+		return lf / rf
+	case PERCENT:
+		return math.Mod(lf, rf)
+	}
+
+	// unreachable
+	return nil
+}
+
+// intArithmetic returns false when the result cannot be represented as an int64, in
+// which case the caller falls back to float arithmetic.
+func intArithmetic(operator TokenType, lv, rv int64) (interface{}, bool) {
+	switch operator {
+	case PLUS:
+		res := lv + rv
+		if (lv > 0 && rv > 0 && res < 0) || (lv < 0 && rv < 0 && res >= 0) {
+			return nil, false
+		}
+
+		return res, true
+	case MINUS:
+		res := lv - rv
+		if (rv > 0 && res > lv) || (rv < 0 && res < lv) {
+			return nil, false
+		}
+
+		return res, true
+	case STAR:
+		if lv == 0 || rv == 0 {
+			return int64(0), true
+		}
+
+		res := lv * rv
+		if res/rv != lv || (lv == -1 && rv == math.MinInt64) || (rv == -1 && lv == math.MinInt64) {
+			return nil, false
+		}
+
+		return res, true
+	case SLASH:
+		// division only stays integral when it is exact
+		if rv == 0 || (lv == math.MinInt64 && rv == -1) || lv%rv != 0 {
+			return nil, false
+		}
+
+		return lv / rv, true
+	case PERCENT:
+		if rv == 0 {
+			return nil, false
+		}
+
+		if rv == -1 {
+			// avoids overflowing on math.MinInt64
+			return int64(0), true
+		}
+
+		return lv % rv, true
+	}
+
+	return nil, false
+}
+
+func compareNumbers(operator TokenType, leftVal, rightVal interface{}) bool {
+	lv, ok := leftVal.(int64)
+	rv, ok2 := rightVal.(int64)
+	if !ok || !ok2 {
+		lf, rf := toFloat(leftVal), toFloat(rightVal)
+
+		switch operator {
+		case LESS:
+			return lf < rf
+		case LESS_EQUAL:
+			return lf <= rf
+		case GREATER:
+			return lf > rf
+		case GREATER_EQUAL:
+			return lf >= rf
+		}
+
+		return false
+	}
+
+	switch operator {
+	case LESS:
+		return lv < rv
+	case LESS_EQUAL:
+		return lv <= rv
+	case GREATER:
+		return lv > rv
+	case GREATER_EQUAL:
+		return lv >= rv
+	}
+
+	return false
+}
+
+func negateNumber(val interface{}) interface{} {
+	if v, ok := val.(int64); ok {
+		if v == math.MinInt64 {
+			return -float64(v)
+		}
+
+		return -v
+	}
+
+	return -toFloat(val)
+}
+
+// isEqual implements "==", numbers of different kinds are equal when they hold the
+// same value.
+func isEqual(leftVal, rightVal interface{}) bool {
+	if isBigNumber(leftVal) || isBigNumber(rightVal) {
+		return isBigEqual(leftVal, rightVal)
+	}
+
+	if isNumber(leftVal) && isNumber(rightVal) {
+		lv, ok := leftVal.(int64)
+		rv, ok2 := rightVal.(int64)
+		if ok && ok2 {
+			return lv == rv
+		}
+
+		return toFloat(leftVal) == toFloat(rightVal)
+	}
+
+	return leftVal == rightVal
+}
+
+// formatNumber is the canonical runtime representation of a number: integers print
+// their digits and integral floats print without a trailing ".0".
+func formatNumber(val interface{}) string {
+	switch v := val.(type) {
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		if math.Abs(v) >= 1e21 || (v != 0 && math.Abs(v) < 1e-6) {
+			return strconv.FormatFloat(v, 'g', -1, 64)
+		}
+
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+
+	return fmt.Sprintf("%v", val)
+}
+
+// formatNumberLiteral renders a number the way the tokenize and parse commands
+// expect, always with a fractional part.
+func formatNumberLiteral(val interface{}) string {
+	switch v := val.(type) {
+	case int64:
+		return strconv.FormatInt(v, 10) + ".0"
+	case float64:
+		if v == math.Trunc(v) && !math.IsInf(v, 0) {
+			return strconv.FormatFloat(v, 'f', 1, 64)
+		}
+
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+
+	return fmt.Sprintf("%v", val)
+}
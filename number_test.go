@@ -0,0 +1,71 @@
+package main
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestIntArithmetic(t *testing.T) {
+	tests := []struct {
+		name     string
+		operator TokenType
+		lv, rv   int64
+		want     interface{}
+		ok       bool
+	}{
+		{"add", PLUS, 2, 3, int64(5), true},
+		{"add overflow", PLUS, math.MaxInt64, 1, nil, false},
+		{"add underflow", PLUS, math.MinInt64, -1, nil, false},
+		{"subtract", MINUS, 2, 3, int64(-1), true},
+		{"subtract overflow", MINUS, math.MaxInt64, -1, nil, false},
+		{"subtract underflow", MINUS, math.MinInt64, 1, nil, false},
+		{"multiply", STAR, -4, 3, int64(-12), true},
+		{"multiply by zero", STAR, math.MaxInt64, 0, int64(0), true},
+		{"multiply overflow", STAR, math.MaxInt64, 2, nil, false},
+		{"multiply MinInt64 by -1", STAR, math.MinInt64, -1, nil, false},
+		{"exact division", SLASH, 12, 4, int64(3), true},
+		{"inexact division", SLASH, 7, 2, nil, false},
+		{"division by zero", SLASH, 1, 0, nil, false},
+		{"divide MinInt64 by -1", SLASH, math.MinInt64, -1, nil, false},
+		{"modulo", PERCENT, 7, 3, int64(1), true},
+		{"negative modulo", PERCENT, -7, 3, int64(-1), true},
+		{"modulo by zero", PERCENT, 7, 0, nil, false},
+		{"MinInt64 modulo -1", PERCENT, math.MinInt64, -1, int64(0), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := intArithmetic(tt.operator, tt.lv, tt.rv)
+			if ok != tt.ok || !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got (%#v, %v), want (%#v, %v)", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestNumberArithmetic(t *testing.T) {
+	tests := []struct {
+		name        string
+		operator    TokenType
+		left, right interface{}
+		want        interface{}
+	}{
+		{"ints stay ints", PLUS, int64(2), int64(3), int64(5)},
+		{"overflow falls back to float", PLUS, int64(math.MaxInt64), int64(1), 9223372036854775808.0},
+		{"inexact division falls back to float", SLASH, int64(7), int64(2), 3.5},
+		{"division by zero", SLASH, int64(1), int64(0), math.Inf(1)},
+		{"mixed operands", STAR, int64(2), 1.5, 3.0},
+		{"floats", MINUS, 1.5, 0.25, 1.25},
+		{"float modulo", PERCENT, 7.5, 2.0, 1.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := numberArithmetic(tt.operator, tt.left, tt.right)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
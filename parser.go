@@ -9,12 +9,27 @@ import (
 type Parser struct {
 	tokens []*Token
 	pos    int
+
+	// scopes holds the names declared in every enclosing scope, mapped to whether they
+	// are constants, so that assignments to constants are rejected while parsing when
+	// possible.
+	scopes []map[string]bool
+
+	// function is the index in scopes of the scope of the innermost function being
+	// parsed. A function body may run after the scopes outside of it declare more
+	// names, so assignments to the names it resolves there are checked at runtime.
+	function int
+
+	// generator is set while parsing the body of a generator function, the only place
+	// yield is allowed.
+	generator bool
 }
 
 func NewParser(tokens []*Token) *Parser {
 	return &Parser{
 		tokens: tokens,
 		pos:    -1,
+		scopes: []map[string]bool{{}},
 	}
 }
 
@@ -23,53 +38,108 @@ func NewParser(tokens []*Token) *Parser {
 //	program        → declaration* EOF ;
 //
 //	declaration    → classDecl
+//					 | traitDecl
+//					 | interfaceDecl
+//					 | enumDecl
 //					 | funDecl
 //					 | varDecl
+//					 | constDecl
 //	 				 | statement ;
 //
 // 	classDecl      → "class" IDENTIFIER ( "<" IDENTIFIER )?
-// 				     "{" function* "}" ;
+// 				     ( "with" IDENTIFIER ( "," IDENTIFIER )* )?
+// 				     ( "implements" IDENTIFIER ( "," IDENTIFIER )* )?
+// 				     "{" classMember* "}" ;
+// 	classMember    → "static"? function
+// 				     | "static" IDENTIFIER ( "=" expression )? ";"
+// 				     | IDENTIFIER block
+// 				     | IDENTIFIER annotation ";"
+// 				     | "set" function ;
+// 	traitDecl      → "trait" IDENTIFIER "{" method* "}" ;
+// 	method         → function | IDENTIFIER block | "set" function
+// 				     | "abstract" signature ";" ;
+// 	interfaceDecl  → "interface" IDENTIFIER "{" ( signature ";" )* "}" ;
+// 	signature      → IDENTIFIER "(" parameters? ")" annotation? ;
+// 	enumDecl       → "enum" IDENTIFIER "{" ( IDENTIFIER ( "," IDENTIFIER )* ","? )? "}" ;
 //	funDecl        → "fun" function ;
-//	function       → IDENTIFIER "(" parameters? ")" block ;
-// 	parameters     → IDENTIFIER ( "," IDENTIFIER )* ;
-//  varDecl        → "var" IDENTIFIER ( "=" expression )? ";" ;
+//	function       → "*"? IDENTIFIER "(" parameters? ")" annotation? block ;
+// 	parameters     → parameter ( "," parameter )* ;
+// 	parameter      → IDENTIFIER annotation? ( "=" expression )?
+//					 | "..." IDENTIFIER annotation? ;
+//	annotation     → ":" ( IDENTIFIER | "nil" ) "?"? ;
+//  varDecl        → "var" IDENTIFIER annotation? ( "=" expression )? ";"
+//					 | "var" destructure "=" expression ";" ;
+//	constDecl      → "const" ( IDENTIFIER annotation? | destructure ) "=" expression ";" ;
+//	destructure    → "[" ( target ( "," target )* )? ( ","? "..." target )? "]"
+//					 | "{" IDENTIFIER ( "," IDENTIFIER )* "}" ;
+//	target         → IDENTIFIER | destructure ;
 //	statement      → exprStmt
 //					 | forStmt
 //					 | ifStmt
 //					 | printStmt
 //					 | returnStmt
 //					 | whileStmt
+//					 | matchStmt
+//					 | yieldStmt
+//					 | selectStmt
 //					 | block ;
 //
 //	returnStmt     → "return" expression? ";" ;
+//	yieldStmt      → "yield" expression? ";" ;
 //	forStmt        → "for" "(" ( varDecl | exprStmt | ";" )
 //					  expression? ";"
-//					  expression? ")" statement ;
+//					  expression? ")" statement
+//					 | "for" "(" "var" target "in" expression ")" statement ;
 //	whileStmt      → "while" "(" expression ")" statement ;
 //	ifStmt         → "if" "(" expression ")" statement
 //					  ( "else" statement )? ;
 //
+//	matchStmt      → "match" "(" expression ")" "{" ( matchCase "=>" statement )* "}" ;
+//	matchExpr      → "match" "(" expression ")" "{" ( matchCase "=>" expression ";" )* "}" ;
+//	matchCase      → "case" pattern ( "," pattern )* ( "if" expression )? ;
+//	pattern        → "_" | IDENTIFIER | "-"? NUMBER | STRING | "true" | "false" | "nil"
+//					 | IDENTIFIER ( "." IDENTIFIER )+
+//					 | IDENTIFIER "(" fieldPatterns? ")"
+//					 | "[" ( pattern ( "," pattern )* )? ( ","? "..." IDENTIFIER )? "]" ;
+//	fieldPatterns  → pattern ( "," pattern )* ( "," IDENTIFIER ":" pattern )*
+//					 | IDENTIFIER ":" pattern ( "," IDENTIFIER ":" pattern )* ;
+//
+//	selectStmt     → "select" "{" ( "case" selectCase "=>" statement )*
+//					  ( "case" "_" "=>" statement )? "}" ;
+//	selectCase     → ( "var" IDENTIFIER "=" )? call "." "recv" "(" ")"
+//					 | call "." "send" "(" expression ")" ;
+//
 //	block          → "{" declaration* "}" ;
 //	exprStmt       → expression ";" ;
 //	printStmt      → "print" expression ";" ;
 //	expression     → assignment ;
-//	assignment     → IDENTIFIER "=" assignment
-//					 | logic_or ;
-//	assignment     → ( call "." )? IDENTIFIER "=" assignment
-//					 | logic_or ;
+//	assignment     → ( call "." )? IDENTIFIER assignOp assignment
+//					 | call "[" expression "]" assignOp assignment
+//					 | "[" arguments? "]" "=" assignment
+//					 | conditional ;
+//	assignOp       → "=" | "+=" | "-=" | "*=" | "/=" | "%=" ;
+//	conditional    → coalesce ( "?" expression ":" conditional )? ;
+//	coalesce       → logic_or ( "??" logic_or )* ;
 //	logic_or       → logic_and ( "or" logic_and )* ;
 //	logic_and      → equality ( "and" equality )* ;
 //	equality       → comparison ( ( "!=" | "==" ) comparison )* ;
-//	comparison     → term ( ( ">" | ">=" | "<" | "<=" ) term )* ;
+//	comparison     → term ( ( ">" | ">=" | "<" | "<=" | "is" ) term )* ;
 //	term           → factor ( ( "-" | "+" ) factor )* ;
-//	factor         → unary ( ( "/" | "*" ) unary )* ;
-//	unary          → ( "!" | "-" ) unary | call ;
-//	call           → primary ( "(" arguments? ")" | "." IDENTIFIER )* ;
-//  arguments      → expression ( "," expression )* ;
-//	primary        → "true" | "false" | "nil"
+//	factor         → unary ( ( "/" | "*" | "%" ) unary )* ;
+//	unary          → ( "!" | "-" | "++" | "--" | "await" ) unary | "spawn" call
+//					 | call ( "++" | "--" )? ;
+//	call           → primary ( "(" callArgs? ")" | ( "." | "?." ) IDENTIFIER
+//					 | "[" expression "]" )* ;
+//	callArgs       → arguments ( "," namedArgs )? | namedArgs ;
+//	namedArgs      → IDENTIFIER ":" expression ( "," IDENTIFIER ":" expression )* ;
+//  arguments      → argument ( "," argument )* ;
+//	argument       → "..."? expression ;
+//	primary        → "true" | "false" | "nil" | "this"
 //					 | NUMBER | STRING
 //				     | "(" expression ")"
-//				     | IDENTIFIER ;
+//				     | "[" arguments? "]"
+//				     | IDENTIFIER | "super" "." IDENTIFIER
+//				     | matchExpr ;
 
 func (p *Parser) NextDeclaration() (Statement, error) {
 	return p.parseDeclaration()
@@ -84,9 +154,15 @@ func (p *Parser) parseDeclaration() (Statement, error) {
 	switch token.Type {
 	case CLASS:
 		return p.parseClassDeclaration()
+	case TRAIT:
+		return p.parseTraitDeclaration()
+	case INTERFACE:
+		return p.parseInterfaceDeclaration()
+	case ENUM:
+		return p.parseEnumDeclaration()
 	case FUN:
 		return p.parseFunDeclaration()
-	case VAR:
+	case VAR, CONST:
 		return p.parseVarDeclaration()
 	}
 
@@ -105,6 +181,7 @@ func (p *Parser) parseClassDeclaration() (Statement, error) {
 	}
 
 	className := token.Lexeme
+	p.declare(className, false)
 
 	var superClass *IdentifierExpr
 
@@ -125,12 +202,27 @@ func (p *Parser) parseClassDeclaration() (Statement, error) {
 		}
 	}
 
+	traits, err := p.parseClassClause("with")
+	if err != nil {
+		return nil, err
+	}
+
+	interfaces, err := p.parseClassClause("implements")
+	if err != nil {
+		return nil, err
+	}
+
 	_, err = p.match(LEFT_BRACE)
 	if err != nil {
 		return nil, err
 	}
 
-	var methods []*FunDeclStmt
+	decl := ClassDeclStmt{
+		Name:       className,
+		SuperClass: superClass,
+		Traits:     traits,
+		Interfaces: interfaces,
+	}
 
 	for {
 		_, err := p.match(RIGHT_BRACE)
@@ -142,409 +234,1498 @@ func (p *Parser) parseClassDeclaration() (Statement, error) {
 			return nil, err
 		}
 
-		m, err := p.parseFunction()
+		_, err = p.match(STATIC)
+		if err == nil {
+			err = p.parseStaticMember(&decl)
+			if err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		err = p.parseMethod(&decl.ClassMembers)
 		if err != nil {
 			return nil, err
 		}
-
-		methods = append(methods, m)
 	}
 
-	return &ClassDeclStmt{
-		Name:       className,
-		SuperClass: superClass,
-		Methods:    methods,
-	}, nil
+	return &decl, nil
 }
 
-func (p *Parser) parseFunDeclaration() (Statement, error) {
-	_, err := p.match(FUN)
+// parseClassClause parses an optional list of names introduced by keyword, which is
+// only a keyword in a class declaration header.
+func (p *Parser) parseEnumDeclaration() (Statement, error) {
+	_, err := p.match(ENUM)
 	if err != nil {
 		return nil, err
 	}
 
-	return p.parseFunction()
-}
-
-func (p *Parser) parseFunction() (*FunDeclStmt, error) {
 	token, err := p.match(IDENTIFIER)
 	if err != nil {
 		return nil, err
 	}
 
-	funName := token.Lexeme
+	p.declare(token.Lexeme, false)
 
-	token, err = p.match(LEFT_PAREN)
+	_, err = p.match(LEFT_BRACE)
 	if err != nil {
 		return nil, err
 	}
 
-	var params []IdentifierExpr
+	decl := EnumDeclStmt{Name: token.Lexeme}
 
-	token, err = p.match(RIGHT_PAREN)
-	if err != nil {
-		params, err = p.parseParameters()
-		if err != nil {
+	for {
+		_, err := p.match(RIGHT_BRACE)
+		if err == nil {
+			break
+		}
+
+		if errors.Is(err, ErrUnexpectedEOF) {
 			return nil, err
 		}
 
-		_, err = p.match(RIGHT_PAREN)
+		if len(decl.Values) > 0 {
+			_, err = p.match(COMMA)
+			if err != nil {
+				return nil, err
+			}
+
+			// a trailing comma is allowed
+			_, err = p.match(RIGHT_BRACE)
+			if err == nil {
+				break
+			}
+		}
+
+		value, err := p.match(IDENTIFIER)
 		if err != nil {
 			return nil, err
 		}
-	}
 
-	block, err := p.parseBlockStatement()
-	if err != nil {
-		return nil, err
+		if value.Lexeme == "values" {
+			return nil, fmt.Errorf("[line %d] Error at 'values': Enum values cannot be called values.", value.Line)
+		}
+
+		if slices.Contains(decl.Values, value.Lexeme) {
+			return nil, fmt.Errorf("[line %d] Error at '%s': Enum %s already has a value called %s.", value.Line, value.Lexeme, decl.Name, value.Lexeme)
+		}
+
+		decl.Values = append(decl.Values, value.Lexeme)
 	}
 
-	return &FunDeclStmt{
-		Name:   funName,
-		Params: params,
-		Body:   block,
-	}, nil
+	return &decl, nil
 }
 
-func (p *Parser) parseParameters() ([]IdentifierExpr, error) {
-	var params []IdentifierExpr
-
-	token, err := p.match(IDENTIFIER)
-	if err != nil {
-		return nil, err
+func (p *Parser) parseClassClause(keyword string) ([]*IdentifierExpr, error) {
+	next, ok := p.peek()
+	if !ok || !next.Type.Is(IDENTIFIER) || next.Lexeme != keyword {
+		return nil, nil
 	}
 
-	params = append(params, IdentifierExpr{
-		Name: token.Lexeme,
-		Line: token.Line,
-	})
+	p.nextToken()
 
-	for {
-		token, err := p.match(COMMA)
-		if err != nil {
-			break
-		}
+	var names []*IdentifierExpr
 
-		token, err = p.match(IDENTIFIER)
+	for {
+		token, err := p.match(IDENTIFIER)
 		if err != nil {
 			return nil, err
 		}
 
-		params = append(params, IdentifierExpr{
+		names = append(names, &IdentifierExpr{
 			Name: token.Lexeme,
 			Line: token.Line,
 		})
+
+		_, err = p.match(COMMA)
+		if err != nil {
+			break
+		}
 	}
 
-	return params, nil
+	return names, nil
 }
 
-func (p *Parser) parseVarDeclaration() (Statement, error) {
-	token, err := p.match(VAR)
+func (p *Parser) parseTraitDeclaration() (Statement, error) {
+	_, err := p.match(TRAIT)
 	if err != nil {
 		return nil, err
 	}
 
-	token, err = p.match(IDENTIFIER)
+	token, err := p.match(IDENTIFIER)
 	if err != nil {
 		return nil, err
 	}
 
-	varName := token.Lexeme
-	var expr Expression = &NilExpr{}
-
-	token, err = p.match(SEMICOLON)
+	_, err = p.match(LEFT_BRACE)
 	if err != nil {
-		token, err = p.match(EQUAL)
-		if err != nil {
-			return nil, err
-		}
+		return nil, err
+	}
 
-		expr, err = p.parseExpression()
-		if err != nil {
-			if errors.Is(err, ErrNoMoreTokens) {
-				return nil, fmt.Errorf("[line %d] Error: Expected expression.", token.Line)
-			}
+	p.declare(token.Lexeme, false)
+
+	decl := TraitDeclStmt{Name: token.Lexeme}
 
+	for {
+		_, err := p.match(RIGHT_BRACE)
+		if err == nil {
+			break
+		}
+
+		if errors.Is(err, ErrUnexpectedEOF) {
 			return nil, err
 		}
 
-		token, err = p.match(SEMICOLON)
+		err = p.parseMethod(&decl.ClassMembers)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	return &VarDeclStmt{
-		Name: varName,
-		Expr: expr,
-	}, nil
-}
-
-func (p *Parser) parseStatement() (Statement, error) {
-	token, ok := p.peek()
-	if !ok {
-		return nil, ErrNoMoreTokens
-	}
-
-	switch token.Type {
-	case PRINT:
-		return p.parsePrintStatement()
-	case LEFT_BRACE:
-		return p.parseBlockStatement()
-	case IF:
-		return p.parseIfStatement()
-	case WHILE:
-		return p.parseWhileStatement()
-	case FOR:
-		return p.parseForStatement()
-	case RETURN:
-		return p.parseReturnStatement()
-	}
-
-	return p.parseExprStatement()
+	return &decl, nil
 }
 
-func (p *Parser) parsePrintStatement() (Statement, error) {
-	_, err := p.match(PRINT)
+// parseMethod parses an instance method, which can also be a getter declared without a
+// parameter list or a setter prefixed with "set".
+func (p *Parser) parseInterfaceDeclaration() (Statement, error) {
+	_, err := p.match(INTERFACE)
 	if err != nil {
 		return nil, err
 	}
 
-	expr, err := p.parseExpression()
+	token, err := p.match(IDENTIFIER)
 	if err != nil {
 		return nil, err
 	}
 
-	_, err = p.match(SEMICOLON)
+	_, err = p.match(LEFT_BRACE)
 	if err != nil {
 		return nil, err
 	}
 
-	return &PrintStmt{Expr: expr}, nil
-}
-
-func (p *Parser) parseBlockStatement() (Statement, error) {
-	_, err := p.match(LEFT_BRACE)
-	if err != nil {
-		return nil, err
-	}
+	p.declare(token.Lexeme, false)
 
-	var stmts []Statement
+	decl := InterfaceDeclStmt{Name: token.Lexeme}
 
 	for {
 		_, err := p.match(RIGHT_BRACE)
-		if err != nil {
-			if errors.Is(err, ErrUnexpectedEOF) {
-				return nil, err
-			}
+		if err == nil {
+			break
+		}
 
-			stmt, err := p.parseDeclaration()
-			if err != nil {
-				return nil, err
-			}
+		if errors.Is(err, ErrUnexpectedEOF) {
+			return nil, err
+		}
 
-			stmts = append(stmts, stmt)
-		} else {
-			break
+		m, err := p.parseSignature()
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = p.match(SEMICOLON)
+		if err != nil {
+			return nil, err
 		}
+
+		decl.Methods = append(decl.Methods, m)
 	}
 
-	return &BlockStmt{
-		Stmts: stmts,
-	}, nil
+	return &decl, nil
 }
 
-func (p *Parser) parseIfStatement() (Statement, error) {
-	_, err := p.match(IF)
-	if err != nil {
-		return nil, err
-	}
+func (p *Parser) parseMethod(decl *ClassMembers) error {
+	if next, ok := p.peek(); ok && next.Type.Is(STAR) {
+		m, err := p.parseFunction()
+		if err != nil {
+			return err
+		}
 
-	_, err = p.match(LEFT_PAREN)
-	if err != nil {
-		return nil, err
-	}
+		decl.Methods = append(decl.Methods, m)
 
-	condition, err := p.parseExpression()
-	if err != nil {
-		return nil, err
+		return nil
 	}
 
-	_, err = p.match(RIGHT_PAREN)
+	token, err := p.match(IDENTIFIER)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	then, err := p.parseStatement()
-	if err != nil {
-		return nil, err
+	next, ok := p.peek()
+	if !ok {
+		return fmt.Errorf("Error: Expected '(', got %w.", ErrUnexpectedEOF)
 	}
 
-	var els Statement = &NilStmt{}
+	switch {
+	case next.Type.Is(COLON):
+		fieldType, err := p.parseTypeAnnotation()
+		if err != nil {
+			return err
+		}
 
-	_, err = p.match(ELSE)
-	if err == nil {
-		els, err = p.parseStatement()
+		_, err = p.match(SEMICOLON)
 		if err != nil {
-			return nil, err
+			return err
 		}
-	}
 
-	return &IfStmt{
-		Condition: condition,
-		Then:      then,
-		Else:      els,
-	}, nil
-}
+		decl.Fields = append(decl.Fields, &FieldDecl{
+			Name: token.Lexeme,
+			Type: fieldType,
+		})
+	case next.Type.Is(LEFT_BRACE):
+		generator, function := p.generator, p.function
+		p.generator, p.function = false, len(p.scopes)
 
-func (p *Parser) parseWhileStatement() (Statement, error) {
-	_, err := p.match(WHILE)
-	if err != nil {
-		return nil, err
-	}
+		body, err := p.parseBlockStatement()
+		p.generator, p.function = generator, function
+		if err != nil {
+			return err
+		}
 
-	_, err = p.match(LEFT_PAREN)
-	if err != nil {
-		return nil, err
+		decl.Getters = append(decl.Getters, &FunDeclStmt{
+			Name: token.Lexeme,
+			Body: body,
+		})
+	case token.Lexeme == "set" && next.Type.Is(IDENTIFIER):
+		m, err := p.parseFunction()
+		if err != nil {
+			return err
+		}
+
+		if len(m.Params) != 1 {
+			return fmt.Errorf("[line %d] Error: Setter '%s' must take exactly one parameter.", next.Line, m.Name)
+		}
+
+		decl.Setters = append(decl.Setters, m)
+	case token.Lexeme == "abstract" && next.Type.Is(IDENTIFIER):
+		m, err := p.parseSignature()
+		if err != nil {
+			return err
+		}
+
+		_, err = p.match(SEMICOLON)
+		if err != nil {
+			return err
+		}
+
+		decl.Abstract = append(decl.Abstract, m)
+	default:
+		p.goBack(1)
+
+		m, err := p.parseFunction()
+		if err != nil {
+			return err
+		}
+
+		decl.Methods = append(decl.Methods, m)
 	}
 
-	condition, err := p.parseExpression()
+	return nil
+}
+
+func (p *Parser) parseStaticMember(decl *ClassDeclStmt) error {
+	token, err := p.match(IDENTIFIER)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	_, err = p.match(RIGHT_PAREN)
+	next, ok := p.peek()
+	if ok && next.Type.Is(LEFT_PAREN) {
+		p.goBack(1)
+
+		m, err := p.parseFunction()
+		if err != nil {
+			return err
+		}
+
+		decl.StaticMethods = append(decl.StaticMethods, m)
+
+		return nil
+	}
+
+	var expr Expression
+
+	_, err = p.match(EQUAL)
+	if err == nil {
+		expr, err = p.parseExpression()
+		if err != nil {
+			if errors.Is(err, ErrNoMoreTokens) {
+				return fmt.Errorf("[line %d] Error: Expected expression.", token.Line)
+			}
+
+			return err
+		}
+	}
+
+	_, err = p.match(SEMICOLON)
+	if err != nil {
+		return err
+	}
+
+	decl.StaticFields = append(decl.StaticFields, &VarDeclStmt{
+		Name: token.Lexeme,
+		Expr: expr,
+	})
+
+	return nil
+}
+
+func (p *Parser) parseFunDeclaration() (Statement, error) {
+	_, err := p.match(FUN)
 	if err != nil {
 		return nil, err
 	}
 
-	body, err := p.parseStatement()
+	fun, err := p.parseFunction()
 	if err != nil {
 		return nil, err
 	}
 
-	return &WhileStmt{
-		Condition: condition,
-		Body:      body,
-	}, nil
+	p.declare(fun.Name, false)
+
+	return fun, nil
 }
 
-func (p *Parser) parseForStatement() (Statement, error) {
-	_, err := p.match(FOR)
+func (p *Parser) parseFunction() (*FunDeclStmt, error) {
+	function := p.function
+	p.function = len(p.scopes)
+
+	defer func() {
+		p.function = function
+	}()
+
+	_, err := p.match(STAR)
+	isGenerator := err == nil
+
+	fun, err := p.parseSignature()
 	if err != nil {
 		return nil, err
 	}
 
-	_, err = p.match(LEFT_PAREN)
+	fun.Generator = isGenerator
+
+	p.beginScope()
+	defer p.endScope()
+
+	for _, param := range fun.Params {
+		p.declare(param.Name, false)
+	}
+
+	generator := p.generator
+	p.generator = isGenerator
+
+	defer func() {
+		p.generator = generator
+	}()
+
+	fun.Body, err = p.parseBlockStatement()
 	if err != nil {
 		return nil, err
 	}
 
-	token, ok := p.peek()
-	if !ok {
-		return nil, fmt.Errorf("Error: Expected statement, got EOF.")
+	return fun, nil
+}
+
+// parseSignature parses a function name and its parameters, leaving the body empty.
+func (p *Parser) parseSignature() (*FunDeclStmt, error) {
+	token, err := p.match(IDENTIFIER)
+	if err != nil {
+		return nil, err
 	}
 
-	var initializer Statement = &NilStmt{}
+	funName := token.Lexeme
 
-	switch token.Type {
-	case VAR:
-		i, err := p.parseVarDeclaration()
+	token, err = p.match(LEFT_PAREN)
+	if err != nil {
+		return nil, err
+	}
+
+	var params []Parameter
+
+	token, err = p.match(RIGHT_PAREN)
+	if err != nil {
+		params, err = p.parseParameters()
 		if err != nil {
 			return nil, err
 		}
 
-		initializer = i
-	case SEMICOLON:
-		p.nextToken()
-	default:
-		i, err := p.parseExprStatement()
+		_, err = p.match(RIGHT_PAREN)
 		if err != nil {
 			return nil, err
 		}
+	}
 
-		initializer = i
+	returnType, err := p.parseTypeAnnotation()
+	if err != nil {
+		return nil, err
 	}
 
-	var condition Expression = &NilExpr{}
+	return &FunDeclStmt{
+		Name:       funName,
+		Params:     params,
+		ReturnType: returnType,
+	}, nil
+}
 
-	_, err = p.match(SEMICOLON)
+// parseTypeAnnotation parses the ": type" annotation of a variable, a parameter or a
+// return value, it returns nil when there is none.
+func (p *Parser) parseTypeAnnotation() (*TypeAnnotation, error) {
+	_, err := p.match(COLON)
 	if err != nil {
-		expr, err := p.parseExpression()
+		return nil, nil
+	}
+
+	token, err := p.match(IDENTIFIER, NIL)
+	if err != nil {
+		return nil, err
+	}
+
+	t := TypeAnnotation{Name: token.Lexeme, Line: token.Line}
+
+	_, err = p.match(QUESTION)
+	t.Nilable = err == nil
+
+	return &t, nil
+}
+
+func (p *Parser) parseParameters() ([]Parameter, error) {
+	var params []Parameter
+
+	for {
+		param, err := p.parseParameter()
 		if err != nil {
 			return nil, err
 		}
 
-		_, err = p.match(SEMICOLON)
+		if len(params) > 0 {
+			prev := params[len(params)-1]
+
+			if prev.Rest {
+				return nil, fmt.Errorf("[line %d] Error at '%s': Rest parameter must be the last one.", prev.Line, prev.Name)
+			}
+
+			if prev.Default != nil && param.Default == nil && !param.Rest {
+				return nil, fmt.Errorf("[line %d] Error at '%s': Parameter without a default value cannot follow one with a default value.", param.Line, param.Name)
+			}
+		}
+
+		for _, prev := range params {
+			if prev.Name == param.Name {
+				return nil, fmt.Errorf("[line %d] Error at '%s': Function already has a parameter called %s.", param.Line, param.Name, param.Name)
+			}
+		}
+
+		params = append(params, param)
+
+		_, err = p.match(COMMA)
 		if err != nil {
-			return nil, err
+			break
 		}
+	}
 
-		condition = expr
+	return params, nil
+}
+
+func (p *Parser) parseParameter() (Parameter, error) {
+	_, err := p.match(ELLIPSIS)
+	isRest := err == nil
+
+	token, err := p.match(IDENTIFIER)
+	if err != nil {
+		return Parameter{}, err
 	}
 
-	var increment Expression = &NilExpr{}
+	param := Parameter{
+		Name: token.Lexeme,
+		Line: token.Line,
+		Rest: isRest,
+	}
 
-	_, err = p.match(RIGHT_PAREN)
+	param.Type, err = p.parseTypeAnnotation()
 	if err != nil {
-		expr, err := p.parseExpression()
+		return Parameter{}, err
+	}
+
+	if isRest {
+		return param, nil
+	}
+
+	token, err = p.match(EQUAL)
+	if err == nil {
+		param.Default, err = p.parseExpression()
+		if err != nil {
+			if errors.Is(err, ErrNoMoreTokens) {
+				return Parameter{}, fmt.Errorf("[line %d] Error: Expected expression.", token.Line)
+			}
+
+			return Parameter{}, err
+		}
+	}
+
+	return param, nil
+}
+
+func (p *Parser) parseVarDeclaration() (Statement, error) {
+	token, err := p.match(VAR, CONST)
+	if err != nil {
+		return nil, err
+	}
+
+	isConst := token.Type.Is(CONST)
+
+	if next, ok := p.peek(); ok && (next.Type.Is(LEFT_BRACKET) || next.Type.Is(LEFT_BRACE)) {
+		return p.parseDestructuringDeclaration(isConst)
+	}
+
+	token, err = p.match(IDENTIFIER)
+	if err != nil {
+		return nil, err
+	}
+
+	varName := token.Lexeme
+	var expr Expression
+
+	varType, err := p.parseTypeAnnotation()
+	if err != nil {
+		return nil, err
+	}
+
+	token, err = p.match(SEMICOLON)
+	if err == nil && isConst {
+		return nil, fmt.Errorf("[line %d] Error at '%s': Constant '%s' must be initialized.", token.Line, varName, varName)
+	}
+
+	if err != nil {
+		token, err = p.match(EQUAL)
 		if err != nil {
 			return nil, err
 		}
 
-		_, err = p.match(RIGHT_PAREN)
+		expr, err = p.parseExpression()
 		if err != nil {
+			if errors.Is(err, ErrNoMoreTokens) {
+				return nil, fmt.Errorf("[line %d] Error: Expected expression.", token.Line)
+			}
+
 			return nil, err
 		}
 
-		increment = expr
+		token, err = p.match(SEMICOLON)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	body, err := p.parseStatement()
+	p.declare(varName, isConst)
+
+	return &VarDeclStmt{
+		Name:  varName,
+		Type:  varType,
+		Expr:  expr,
+		Const: isConst,
+	}, nil
+}
+
+func (p *Parser) parseDestructuringDeclaration(isConst bool) (Statement, error) {
+	var names []string
+
+	target, err := p.parseDestructuringTarget(&names)
 	if err != nil {
 		return nil, err
 	}
 
-	// desugaring for-loop to while-loop
-	return &BlockStmt{
-		Stmts: []Statement{
-			initializer,
-			&WhileStmt{
-				Condition: condition,
-				Body: &BlockStmt{
-					Stmts: []Statement{
-						body,
-						&ExprStmt{Expr: increment},
-					},
-				},
-			},
-		},
+	token, err := p.match(EQUAL)
+	if err != nil {
+		return nil, err
+	}
+
+	expr, err := p.parseExpression()
+	if err != nil {
+		if errors.Is(err, ErrNoMoreTokens) {
+			return nil, fmt.Errorf("[line %d] Error: Expected expression.", token.Line)
+		}
+
+		return nil, err
+	}
+
+	_, err = p.match(SEMICOLON)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range names {
+		p.declare(name, isConst)
+	}
+
+	return &DestructureDeclStmt{
+		Target: target,
+		Expr:   expr,
+		Const:  isConst,
+		Line:   token.Line,
 	}, nil
 }
 
-func (p *Parser) parseReturnStatement() (Statement, error) {
-	_, err := p.match(RETURN)
+// parseDestructuringTarget parses a variable name or a list or object pattern of a
+// destructuring declaration, appending the names it declares to names.
+func (p *Parser) parseDestructuringTarget(names *[]string) (Expression, error) {
+	token, ok := p.nextToken()
+	if !ok {
+		return nil, ErrNoMoreTokens
+	}
+
+	switch token.Type {
+	case IDENTIFIER:
+		*names = append(*names, token.Lexeme)
+
+		return &IdentifierExpr{Name: token.Lexeme, Line: token.Line}, nil
+	case LEFT_BRACKET:
+		list := ListExpr{Line: token.Line}
+
+		for {
+			_, err := p.match(RIGHT_BRACKET)
+			if err == nil {
+				return &list, nil
+			}
+
+			if len(list.Elements) > 0 {
+				_, err = p.match(COMMA)
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			spread, err := p.match(ELLIPSIS)
+			if err == nil {
+				rest, err := p.parseDestructuringTarget(names)
+				if err != nil {
+					return nil, err
+				}
+
+				list.Elements = append(list.Elements, &SpreadExpr{Expr: rest, Line: spread.Line})
+
+				_, err = p.match(RIGHT_BRACKET)
+				if err != nil {
+					return nil, err
+				}
+
+				return &list, nil
+			}
+
+			e, err := p.parseDestructuringTarget(names)
+			if err != nil {
+				return nil, err
+			}
+
+			list.Elements = append(list.Elements, e)
+		}
+	case LEFT_BRACE:
+		pattern := ObjectPattern{Line: token.Line}
+
+		for {
+			name, err := p.match(IDENTIFIER)
+			if err != nil {
+				return nil, err
+			}
+
+			pattern.Names = append(pattern.Names, name.Lexeme)
+			*names = append(*names, name.Lexeme)
+
+			_, err = p.match(COMMA)
+			if err != nil {
+				break
+			}
+		}
+
+		_, err := p.match(RIGHT_BRACE)
+		if err != nil {
+			return nil, err
+		}
+
+		return &pattern, nil
+	}
+
+	return nil, fmt.Errorf("[line %d] Error at '%s': Expect variable name.", token.Line, token.Lexeme)
+}
+
+func (p *Parser) parseStatement() (Statement, error) {
+	token, ok := p.peek()
+	if !ok {
+		return nil, ErrNoMoreTokens
+	}
+
+	switch token.Type {
+	case PRINT:
+		return p.parsePrintStatement()
+	case LEFT_BRACE:
+		return p.parseBlockStatement()
+	case IF:
+		return p.parseIfStatement()
+	case WHILE:
+		return p.parseWhileStatement()
+	case FOR:
+		return p.parseForStatement()
+	case RETURN:
+		return p.parseReturnStatement()
+	case MATCH:
+		return p.parseMatchStatement()
+	case YIELD:
+		return p.parseYieldStatement()
+	case SELECT:
+		return p.parseSelectStatement()
+	}
+
+	return p.parseExprStatement()
+}
+
+func (p *Parser) parsePrintStatement() (Statement, error) {
+	token, err := p.match(PRINT)
+	if err != nil {
+		return nil, err
+	}
+
+	expr, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.match(SEMICOLON)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PrintStmt{Expr: expr, Line: token.Line}, nil
+}
+
+func (p *Parser) parseBlockStatement() (Statement, error) {
+	_, err := p.match(LEFT_BRACE)
+	if err != nil {
+		return nil, err
+	}
+
+	p.beginScope()
+	defer p.endScope()
+
+	var stmts []Statement
+
+	for {
+		_, err := p.match(RIGHT_BRACE)
+		if err != nil {
+			if errors.Is(err, ErrUnexpectedEOF) {
+				return nil, err
+			}
+
+			stmt, err := p.parseDeclaration()
+			if err != nil {
+				return nil, err
+			}
+
+			stmts = append(stmts, stmt)
+		} else {
+			break
+		}
+	}
+
+	return &BlockStmt{
+		Stmts: stmts,
+	}, nil
+}
+
+func (p *Parser) parseIfStatement() (Statement, error) {
+	_, err := p.match(IF)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.match(LEFT_PAREN)
+	if err != nil {
+		return nil, err
+	}
+
+	condition, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.match(RIGHT_PAREN)
+	if err != nil {
+		return nil, err
+	}
+
+	then, err := p.parseStatement()
+	if err != nil {
+		return nil, err
+	}
+
+	var els Statement = &NilStmt{}
+
+	_, err = p.match(ELSE)
+	if err == nil {
+		els, err = p.parseStatement()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &IfStmt{
+		Condition: condition,
+		Then:      then,
+		Else:      els,
+	}, nil
+}
+
+func (p *Parser) parseWhileStatement() (Statement, error) {
+	_, err := p.match(WHILE)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.match(LEFT_PAREN)
+	if err != nil {
+		return nil, err
+	}
+
+	condition, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.match(RIGHT_PAREN)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := p.parseStatement()
+	if err != nil {
+		return nil, err
+	}
+
+	return &WhileStmt{
+		Condition: condition,
+		Body:      body,
+	}, nil
+}
+
+func (p *Parser) parseForStatement() (Statement, error) {
+	_, err := p.match(FOR)
+	if err != nil {
+		return nil, err
+	}
+
+	p.beginScope()
+	defer p.endScope()
+
+	_, err = p.match(LEFT_PAREN)
+	if err != nil {
+		return nil, err
+	}
+
+	forIn, err := p.parseForIn()
+	if forIn != nil || err != nil {
+		return forIn, err
+	}
+
+	token, ok := p.peek()
+	if !ok {
+		return nil, fmt.Errorf("Error: Expected statement, got EOF.")
+	}
+
+	var initializer Statement = &NilStmt{}
+
+	switch token.Type {
+	case VAR:
+		i, err := p.parseVarDeclaration()
+		if err != nil {
+			return nil, err
+		}
+
+		initializer = i
+	case SEMICOLON:
+		p.nextToken()
+	default:
+		i, err := p.parseExprStatement()
+		if err != nil {
+			return nil, err
+		}
+
+		initializer = i
+	}
+
+	var condition Expression = &NilExpr{}
+
+	_, err = p.match(SEMICOLON)
+	if err != nil {
+		expr, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = p.match(SEMICOLON)
+		if err != nil {
+			return nil, err
+		}
+
+		condition = expr
+	}
+
+	var increment Expression = &NilExpr{}
+
+	_, err = p.match(RIGHT_PAREN)
+	if err != nil {
+		expr, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = p.match(RIGHT_PAREN)
+		if err != nil {
+			return nil, err
+		}
+
+		increment = expr
+	}
+
+	body, err := p.parseStatement()
+	if err != nil {
+		return nil, err
+	}
+
+	// desugaring for-loop to while-loop
+	return &BlockStmt{
+		Stmts: []Statement{
+			initializer,
+			&WhileStmt{
+				Condition: condition,
+				Body: &BlockStmt{
+					Stmts: []Statement{
+						body,
+						&ExprStmt{Expr: increment},
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+// parseForIn parses the rest of a for-in loop, it returns nil when the loop turns out
+// to be a C-style one.
+func (p *Parser) parseForIn() (Statement, error) {
+	currPos := p.pos
+
+	_, err := p.match(VAR)
+	if err != nil {
+		return nil, nil
+	}
+
+	var names []string
+
+	target, err := p.parseDestructuringTarget(&names)
+	if err != nil {
+		p.goBack(p.pos - currPos)
+		return nil, nil
+	}
+
+	token, err := p.match(IN)
+	if err != nil {
+		p.goBack(p.pos - currPos)
+		return nil, nil
+	}
+
+	iterable, err := p.parseExpression()
+	if err != nil {
+		if errors.Is(err, ErrNoMoreTokens) {
+			return nil, fmt.Errorf("[line %d] Error at 'in': Expect expression.", token.Line)
+		}
+
+		return nil, err
+	}
+
+	_, err = p.match(RIGHT_PAREN)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range names {
+		p.declare(name, false)
+	}
+
+	body, err := p.parseStatement()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ForInStmt{
+		Target:   target,
+		Iterable: iterable,
+		Body:     body,
+		Line:     token.Line,
+	}, nil
+}
+
+func (p *Parser) parseYieldStatement() (Statement, error) {
+	token, err := p.match(YIELD)
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.generator {
+		return nil, fmt.Errorf("[line %d] Error at 'yield': Can't yield outside of a generator.", token.Line)
+	}
+
+	var expr Expression = &NilExpr{}
+
+	_, err = p.match(SEMICOLON)
+	if err != nil {
+		expr, err = p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = p.match(SEMICOLON)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &YieldStmt{Expr: expr}, nil
+}
+
+func (p *Parser) parseReturnStatement() (Statement, error) {
+	token, err := p.match(RETURN)
+	if err != nil {
+		return nil, err
+	}
+
+	var expr Expression = &NilExpr{}
+
+	_, err = p.match(SEMICOLON)
+	if err != nil {
+		expr, err = p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = p.match(SEMICOLON)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &ReturnStmt{Expr: expr, Line: token.Line}, nil
+}
+
+func (p *Parser) parseMatchStatement() (Statement, error) {
+	p.nextToken()
+
+	subject, cases, err := p.parseMatch(func(c *MatchCase) error {
+		body, err := p.parseStatement()
+		if err != nil {
+			return err
+		}
+
+		c.Body = body
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &MatchStmt{Subject: subject, Cases: cases}, nil
+}
+
+// parseMatch parses what follows the "match" keyword, parseBody parses the body of
+// each case, in the scope of the names its patterns bind.
+func (p *Parser) parseMatch(parseBody func(c *MatchCase) error) (Expression, []*MatchCase, error) {
+	_, err := p.match(LEFT_PAREN)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	subject, err := p.parseExpression()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	_, err = p.match(RIGHT_PAREN)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	_, err = p.match(LEFT_BRACE)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var cases []*MatchCase
+
+	for {
+		_, err := p.match(RIGHT_BRACE)
+		if err == nil {
+			break
+		}
+
+		if errors.Is(err, ErrUnexpectedEOF) {
+			return nil, nil, err
+		}
+
+		_, err = p.match(CASE)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		c, err := p.parseMatchCase(parseBody)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		cases = append(cases, c)
+	}
+
+	return subject, cases, nil
+}
+
+func (p *Parser) parseMatchCase(parseBody func(c *MatchCase) error) (*MatchCase, error) {
+	p.beginScope()
+	defer p.endScope()
+
+	var c MatchCase
+
+	for {
+		pattern, err := p.parsePattern()
+		if err != nil {
+			return nil, err
+		}
+
+		c.Patterns = append(c.Patterns, pattern)
+
+		_, err = p.match(COMMA)
+		if err != nil {
+			break
+		}
+	}
+
+	_, err := p.match(IF)
+	if err == nil {
+		c.Guard, err = p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = p.match(FAT_ARROW)
+	if err != nil {
+		return nil, err
+	}
+
+	err = parseBody(&c)
+	if err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+func (p *Parser) parsePattern() (Pattern, error) {
+	token, ok := p.nextToken()
+	if !ok {
+		return nil, ErrNoMoreTokens
+	}
+
+	switch token.Type {
+	case TRUE, FALSE, NIL, NUMBER, STRING:
+		p.goBack(1)
+
+		e, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+
+		return &ValuePattern{Expr: e}, nil
+	case MINUS:
+		number, err := p.match(NUMBER)
+		if err != nil {
+			return nil, err
+		}
+
+		return &ValuePattern{Expr: &UnaryExpr{
+			Unary: string(MINUS),
+			Expr:  &LiteralExpr{Literal: number.Literal, Line: number.Line},
+			Line:  token.Line,
+		}}, nil
+	case LEFT_BRACKET:
+		return p.parseListPattern()
+	case IDENTIFIER:
+		next, ok := p.peek()
+
+		switch {
+		case token.Lexeme == "_":
+			return &WildcardPattern{}, nil
+		case ok && next.Type.Is(DOT):
+			var e Expression = &IdentifierExpr{Name: token.Lexeme, Line: token.Line}
+
+			for {
+				_, err := p.match(DOT)
+				if err != nil {
+					return &ValuePattern{Expr: e}, nil
+				}
+
+				prop, err := p.match(IDENTIFIER)
+				if err != nil {
+					return nil, err
+				}
+
+				e = &ObjectGetExpr{Object: e, Prop: prop.Lexeme, Line: prop.Line}
+			}
+		case ok && next.Type.Is(LEFT_PAREN):
+			return p.parseClassPattern(token)
+		}
+
+		p.declare(token.Lexeme, false)
+
+		return &BindingPattern{Name: token.Lexeme}, nil
+	}
+
+	return nil, fmt.Errorf("[line %d] Error at '%s': Expect pattern.", token.Line, token.Lexeme)
+}
+
+func (p *Parser) parseListPattern() (Pattern, error) {
+	var lp ListPattern
+
+	for {
+		_, err := p.match(RIGHT_BRACKET)
+		if err == nil {
+			return &lp, nil
+		}
+
+		if len(lp.Elements) > 0 {
+			_, err = p.match(COMMA)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		_, err = p.match(ELLIPSIS)
+		if err == nil {
+			token, err := p.match(IDENTIFIER)
+			if err != nil {
+				return nil, err
+			}
+
+			lp.Rest = &WildcardPattern{}
+			if token.Lexeme != "_" {
+				p.declare(token.Lexeme, false)
+				lp.Rest = &BindingPattern{Name: token.Lexeme}
+			}
+
+			_, err = p.match(RIGHT_BRACKET)
+			if err != nil {
+				return nil, err
+			}
+
+			return &lp, nil
+		}
+
+		pattern, err := p.parsePattern()
+		if err != nil {
+			return nil, err
+		}
+
+		lp.Elements = append(lp.Elements, pattern)
+	}
+}
+
+// parseClassPattern parses the fields of a class pattern, positional ones come first
+// and are followed by named ones, like call arguments.
+func (p *Parser) parseClassPattern(class *Token) (Pattern, error) {
+	cp := ClassPattern{
+		Class: &IdentifierExpr{Name: class.Lexeme, Line: class.Line},
+		Line:  class.Line,
+	}
+
+	p.nextToken()
+
+	_, err := p.match(RIGHT_PAREN)
+	if err == nil {
+		return &cp, nil
+	}
+
+	for {
+		token, ok := p.peek()
+		if !ok {
+			return nil, ErrNoMoreTokens
+		}
+
+		if next, ok := p.peekAt(2); ok && token.Type.Is(IDENTIFIER) && next.Type.Is(COLON) {
+			p.nextToken()
+			p.nextToken()
+
+			pattern, err := p.parsePattern()
+			if err != nil {
+				return nil, err
+			}
+
+			cp.Named = append(cp.Named, FieldPattern{Name: token.Lexeme, Pattern: pattern})
+		} else {
+			if len(cp.Named) > 0 {
+				return nil, fmt.Errorf("[line %d] Error at '%s': Positional fields must come before named ones.", token.Line, token.Lexeme)
+			}
+
+			pattern, err := p.parsePattern()
+			if err != nil {
+				return nil, err
+			}
+
+			cp.Args = append(cp.Args, pattern)
+		}
+
+		_, err := p.match(COMMA)
+		if err != nil {
+			break
+		}
+	}
+
+	_, err = p.match(RIGHT_PAREN)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cp, nil
+}
+
+func (p *Parser) parseSelectStatement() (Statement, error) {
+	token, err := p.match(SELECT)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.match(LEFT_BRACE)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := SelectStmt{Line: token.Line}
+
+	for {
+		_, err := p.match(RIGHT_BRACE)
+		if err == nil {
+			break
+		}
+
+		if errors.Is(err, ErrUnexpectedEOF) {
+			return nil, err
+		}
+
+		caseToken, err := p.match(CASE)
+		if err != nil {
+			return nil, err
+		}
+
+		if next, ok := p.peek(); ok && next.Type.Is(IDENTIFIER) && next.Lexeme == "_" {
+			if stmt.Default != nil {
+				return nil, fmt.Errorf("[line %d] Error at '_': Select can only have one default case.", next.Line)
+			}
+
+			p.nextToken()
+
+			_, err = p.match(FAT_ARROW)
+			if err != nil {
+				return nil, err
+			}
+
+			stmt.Default, err = p.parseSelectBody("")
+			if err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		c, err := p.parseSelectCase(caseToken)
+		if err != nil {
+			return nil, err
+		}
+
+		stmt.Cases = append(stmt.Cases, c)
+	}
+
+	return &stmt, nil
+}
+
+// parseSelectCase parses what follows the "case" keyword in a select statement, a
+// channel's send() or recv() call, the latter optionally bound to a variable.
+func (p *Parser) parseSelectCase(caseToken *Token) (*SelectCase, error) {
+	c := SelectCase{Line: caseToken.Line}
+
+	_, err := p.match(VAR)
+	if err == nil {
+		name, err := p.match(IDENTIFIER)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = p.match(EQUAL)
+		if err != nil {
+			return nil, err
+		}
+
+		c.Name = name.Lexeme
+	}
+
+	expr, err := p.parseCall()
+	if err != nil {
+		return nil, err
+	}
+
+	invalid := fmt.Errorf("[line %d] Error at 'case': Select cases must be a channel's send() or recv() call.", caseToken.Line)
+
+	call, ok := expr.(*CallExpr)
+	if !ok || len(call.Named) > 0 {
+		return nil, invalid
+	}
+
+	get, ok := call.Callee.(*ObjectGetExpr)
+	if !ok || get.Optional {
+		return nil, invalid
+	}
+
+	switch {
+	case get.Prop == "recv" && len(call.Args) == 0:
+	case get.Prop == "send" && len(call.Args) == 1 && c.Name == "":
+		c.Value = call.Args[0]
+	default:
+		return nil, invalid
+	}
+
+	if _, ok := c.Value.(*SpreadExpr); ok {
+		return nil, invalid
+	}
+
+	c.Channel = get.Object
+
+	_, err = p.match(FAT_ARROW)
 	if err != nil {
 		return nil, err
 	}
 
-	var expr Expression = &NilExpr{}
-
-	_, err = p.match(SEMICOLON)
+	c.Body, err = p.parseSelectBody(c.Name)
 	if err != nil {
-		expr, err = p.parseExpression()
-		if err != nil {
-			return nil, err
-		}
+		return nil, err
+	}
 
-		_, err = p.match(SEMICOLON)
-		if err != nil {
-			return nil, err
-		}
+	return &c, nil
+}
+
+// parseSelectBody parses the body of a select case in the scope of the variable the
+// case binds, if any.
+func (p *Parser) parseSelectBody(name string) (Statement, error) {
+	p.beginScope()
+	defer p.endScope()
+
+	if name != "" {
+		p.declare(name, false)
 	}
 
-	return &ReturnStmt{Expr: expr}, nil
+	return p.parseStatement()
 }
 
 func (p *Parser) parseExprStatement() (Statement, error) {
@@ -575,13 +1756,18 @@ func (p *Parser) parseAssignment() (Expression, error) {
 	expr, err := p.parseCall()
 	if err != nil {
 		p.goBack(p.pos - currPos)
-		return p.parseLogicOr()
+		return p.parseConditional()
 	}
 
-	token, err := p.match(EQUAL)
+	token, err := p.match(EQUAL, PLUS_EQUAL, MINUS_EQUAL, STAR_EQUAL, SLASH_EQUAL, PERCENT_EQUAL)
 	if err != nil {
 		p.goBack(p.pos - currPos)
-		return p.parseLogicOr()
+		return p.parseConditional()
+	}
+
+	err = p.checkAssignable(expr, token)
+	if err != nil {
+		return nil, err
 	}
 
 	assign, err := p.parseAssignment()
@@ -593,7 +1779,29 @@ func (p *Parser) parseAssignment() (Expression, error) {
 		return nil, err
 	}
 
+	if operator, ok := compoundAssignments[token.Type]; ok {
+		return &UpdateExpr{
+			Target:   expr,
+			Operator: operator,
+			Expr:     assign,
+			Line:     token.Line,
+		}, nil
+	}
+
 	switch v := expr.(type) {
+	case *ListExpr:
+		return &DestructureAssignExpr{
+			Target: v,
+			Expr:   assign,
+			Line:   token.Line,
+		}, nil
+	case *IndexExpr:
+		return &IndexSetExpr{
+			Object: v.Object,
+			Index:  v.Index,
+			Expr:   assign,
+			Line:   v.Line,
+		}, nil
 	case *ObjectGetExpr:
 		return &ObjectSetExpr{
 			Object: v.Object,
@@ -607,9 +1815,61 @@ func (p *Parser) parseAssignment() (Expression, error) {
 			Expr: assign,
 			Line: v.Line,
 		}, nil
-	default:
-		return nil, fmt.Errorf("[line %d] Error: Unkown expression type %v", token.Line, v)
 	}
+
+	// unreachable, checkAssignable rejects any other target
+	return nil, fmt.Errorf("[line %d] Error: Unkown expression type %v", token.Line, expr)
+}
+
+// compoundAssignments maps the compound assignment operators to the binary operator
+// they apply.
+var compoundAssignments = map[TokenType]TokenType{
+	PLUS_EQUAL:    PLUS,
+	MINUS_EQUAL:   MINUS,
+	STAR_EQUAL:    STAR,
+	SLASH_EQUAL:   SLASH,
+	PERCENT_EQUAL: PERCENT,
+}
+
+// checkAssignable returns an error unless expr is a variable, a property or an indexed
+// element that can be assigned to, or for "=" a list of such targets to destructure
+// into.
+func (p *Parser) checkAssignable(expr Expression, token *Token) error {
+	switch v := expr.(type) {
+	case *IdentifierExpr:
+		if p.isConstant(v.Name) {
+			return fmt.Errorf("[line %d] Error at '%s': Cannot assign to constant '%s'.", v.Line, v.Name, v.Name)
+		}
+
+		return nil
+	case *ObjectGetExpr, *IndexExpr:
+		return nil
+	case *ListExpr:
+		if !token.Type.Is(EQUAL) {
+			break
+		}
+
+		for i, e := range v.Elements {
+			if spread, ok := e.(*SpreadExpr); ok {
+				if i != len(v.Elements)-1 {
+					return fmt.Errorf("[line %d] Error at '...': Rest element must be the last one.", spread.Line)
+				}
+
+				e = spread.Expr
+			}
+
+			err := p.checkAssignable(e, token)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	case *OptionalChainExpr:
+		return fmt.Errorf("[line %d] Error at '%s': Cannot assign through an optional chain.", token.Line, token.Lexeme)
+	}
+
+	return fmt.Errorf("[line %d] Error at '%s': Invalid assignment target.", token.Line, token.Lexeme)
 }
 
 func (p *Parser) parseSequenceBinary(parseFunc func() (Expression, error), matcher TokenType, matchers ...TokenType) (Expression, error) {
@@ -667,6 +1927,51 @@ func (p *Parser) parseSequenceLogical(parseFunc func() (Expression, error), matc
 	return e, nil
 }
 
+func (p *Parser) parseConditional() (Expression, error) {
+	cond, err := p.parseCoalesce()
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := p.match(QUESTION)
+	if err != nil {
+		return cond, nil
+	}
+
+	then, err := p.parseExpression()
+	if err != nil {
+		if errors.Is(err, ErrNoMoreTokens) {
+			return nil, fmt.Errorf("[line %d] Error at '?': Expect expression.", token.Line)
+		}
+
+		return nil, err
+	}
+
+	token, err = p.match(COLON)
+	if err != nil {
+		return nil, err
+	}
+
+	elseExpr, err := p.parseConditional()
+	if err != nil {
+		if errors.Is(err, ErrNoMoreTokens) {
+			return nil, fmt.Errorf("[line %d] Error at ':': Expect expression.", token.Line)
+		}
+
+		return nil, err
+	}
+
+	return &ConditionalExpr{
+		Cond: cond,
+		Then: then,
+		Else: elseExpr,
+	}, nil
+}
+
+func (p *Parser) parseCoalesce() (Expression, error) {
+	return p.parseSequenceLogical(p.parseLogicOr, QUESTION_QUESTION)
+}
+
 func (p *Parser) parseLogicOr() (Expression, error) {
 	return p.parseSequenceLogical(p.parseLogicAnd, OR)
 }
@@ -680,7 +1985,7 @@ func (p *Parser) parseEquality() (Expression, error) {
 }
 
 func (p *Parser) parseComparison() (Expression, error) {
-	return p.parseSequenceBinary(p.parseTerm, LESS, LESS_EQUAL, GREATER, GREATER_EQUAL)
+	return p.parseSequenceBinary(p.parseTerm, LESS, LESS_EQUAL, GREATER, GREATER_EQUAL, IS)
 }
 
 func (p *Parser) parseTerm() (Expression, error) {
@@ -688,7 +1993,7 @@ func (p *Parser) parseTerm() (Expression, error) {
 }
 
 func (p *Parser) parseFactor() (Expression, error) {
-	return p.parseSequenceBinary(p.parseUnary, SLASH, STAR)
+	return p.parseSequenceBinary(p.parseUnary, SLASH, STAR, PERCENT)
 }
 
 func (p *Parser) parseUnary() (Expression, error) {
@@ -715,9 +2020,78 @@ func (p *Parser) parseUnary() (Expression, error) {
 			Expr:  u,
 			Line:  token.Line,
 		}, nil
+	case PLUS_PLUS, MINUS_MINUS:
+		p.nextToken()
+
+		target, err := p.parseUnary()
+		if err != nil {
+			if errors.Is(err, ErrNoMoreTokens) {
+				return nil, fmt.Errorf("[line %d] Error at '%s': Expect expression.", token.Line, token.Lexeme)
+			}
+
+			return nil, err
+		}
+
+		return p.increment(target, token, false)
+	case SPAWN:
+		p.nextToken()
+
+		expr, err := p.parseCall()
+		if err != nil && !errors.Is(err, ErrNoMoreTokens) {
+			return nil, err
+		}
+
+		call, ok := expr.(*CallExpr)
+		if !ok {
+			return nil, fmt.Errorf("[line %d] Error at 'spawn': Expect a call after 'spawn'.", token.Line)
+		}
+
+		return &SpawnExpr{Call: call, Line: token.Line}, nil
+	case AWAIT:
+		p.nextToken()
+
+		expr, err := p.parseUnary()
+		if err != nil {
+			if errors.Is(err, ErrNoMoreTokens) {
+				return nil, fmt.Errorf("[line %d] Error at 'await': Expect expression.", token.Line)
+			}
+
+			return nil, err
+		}
+
+		return &AwaitExpr{Expr: expr, Line: token.Line}, nil
+	}
+
+	expr, err := p.parseCall()
+	if err != nil {
+		return nil, err
+	}
+
+	token, err = p.match(PLUS_PLUS, MINUS_MINUS)
+	if err != nil {
+		return expr, nil
+	}
+
+	return p.increment(expr, token, true)
+}
+
+func (p *Parser) increment(target Expression, token *Token, postfix bool) (Expression, error) {
+	err := p.checkAssignable(target, token)
+	if err != nil {
+		return nil, err
 	}
 
-	return p.parseCall()
+	operator := PLUS
+	if token.Type.Is(MINUS_MINUS) {
+		operator = MINUS
+	}
+
+	return &UpdateExpr{
+		Target:   target,
+		Operator: operator,
+		Postfix:  postfix,
+		Line:     token.Line,
+	}, nil
 }
 
 func (p *Parser) parseCall() (Expression, error) {
@@ -726,15 +2100,22 @@ func (p *Parser) parseCall() (Expression, error) {
 		return nil, err
 	}
 
+	// optional is set once the chain contains a "?.", the whole chain then evaluates to
+	// nil as soon as one of them is applied to nil
+	optional := false
+
 	for {
 		token, ok := p.peek()
 		if !ok {
-			return expr, nil
+			return optionalChain(expr, optional), nil
 		}
 
 		switch token.Type {
 		case LEFT_PAREN:
-			var args []Expression
+			call := &CallExpr{
+				Callee: expr,
+				Line:   token.Line,
+			}
 
 			p.nextToken()
 			_, err = p.match(RIGHT_PAREN)
@@ -743,7 +2124,7 @@ func (p *Parser) parseCall() (Expression, error) {
 					return nil, err
 				}
 
-				args, err = p.parseArguments()
+				err = p.parseCallArguments(call)
 				if err != nil {
 					return nil, err
 				}
@@ -754,34 +2135,70 @@ func (p *Parser) parseCall() (Expression, error) {
 				}
 			}
 
-			expr = &CallExpr{
-				Callee: expr,
-				Args:   args,
-				Line:   token.Line,
-			}
-		case DOT:
+			expr = call
+		case DOT, QUESTION_DOT:
 			p.nextToken()
 
+			isOptional := token.Type.Is(QUESTION_DOT)
+			optional = optional || isOptional
+
 			token, err = p.match(IDENTIFIER)
 			if err != nil {
 				return nil, err
 			}
 
+			if isPrivate(token.Lexeme) {
+				if this, ok := expr.(*IdentifierExpr); !ok || this.Name != string(THIS) {
+					return nil, fmt.Errorf("[line %d] Error at '%s': Private members can only be accessed through 'this'.", token.Line, token.Lexeme)
+				}
+			}
+
 			expr = &ObjectGetExpr{
+				Object:   expr,
+				Prop:     token.Lexeme,
+				Optional: isOptional,
+				Line:     token.Line,
+			}
+		case LEFT_BRACKET:
+			p.nextToken()
+
+			index, err := p.parseExpression()
+			if err != nil {
+				if errors.Is(err, ErrNoMoreTokens) {
+					return nil, fmt.Errorf("[line %d] Error at '[': Expect expression.", token.Line)
+				}
+
+				return nil, err
+			}
+
+			_, err = p.match(RIGHT_BRACKET)
+			if err != nil {
+				return nil, err
+			}
+
+			expr = &IndexExpr{
 				Object: expr,
-				Prop:   token.Lexeme,
+				Index:  index,
 				Line:   token.Line,
 			}
 		default:
-			return expr, nil
+			return optionalChain(expr, optional), nil
 		}
 	}
 }
 
+func optionalChain(expr Expression, optional bool) Expression {
+	if !optional {
+		return expr
+	}
+
+	return &OptionalChainExpr{Expr: expr}
+}
+
 func (p *Parser) parseArguments() ([]Expression, error) {
 	var args []Expression
 
-	e, err := p.parseExpression()
+	e, err := p.parseArgument()
 	if err != nil {
 		return nil, err
 	}
@@ -794,7 +2211,7 @@ func (p *Parser) parseArguments() ([]Expression, error) {
 			break
 		}
 
-		exp, err := p.parseExpression()
+		exp, err := p.parseArgument()
 		if err != nil {
 			return nil, err
 		}
@@ -805,6 +2222,69 @@ func (p *Parser) parseArguments() ([]Expression, error) {
 	return args, nil
 }
 
+// parseCallArguments parses the arguments of a call, positional arguments come first
+// and are followed by named ones.
+func (p *Parser) parseCallArguments(call *CallExpr) error {
+	for {
+		token, ok := p.peek()
+		if !ok {
+			return ErrNoMoreTokens
+		}
+
+		if next, ok := p.peekAt(2); ok && token.Type.Is(IDENTIFIER) && next.Type.Is(COLON) {
+			p.nextToken()
+			p.nextToken()
+
+			for _, arg := range call.Named {
+				if arg.Name == token.Lexeme {
+					return fmt.Errorf("[line %d] Error at '%s': Duplicate named argument.", token.Line, token.Lexeme)
+				}
+			}
+
+			e, err := p.parseExpression()
+			if err != nil {
+				return err
+			}
+
+			call.Named = append(call.Named, NamedArgument{
+				Name: token.Lexeme,
+				Expr: e,
+				Line: token.Line,
+			})
+		} else {
+			if len(call.Named) > 0 {
+				return fmt.Errorf("[line %d] Error at '%s': Positional arguments must come before named ones.", token.Line, token.Lexeme)
+			}
+
+			e, err := p.parseArgument()
+			if err != nil {
+				return err
+			}
+
+			call.Args = append(call.Args, e)
+		}
+
+		_, err := p.match(COMMA)
+		if err != nil {
+			return nil
+		}
+	}
+}
+
+func (p *Parser) parseArgument() (Expression, error) {
+	token, err := p.match(ELLIPSIS)
+	if err != nil {
+		return p.parseExpression()
+	}
+
+	e, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+
+	return &SpreadExpr{Expr: e, Line: token.Line}, nil
+}
+
 func (p *Parser) parsePrimary() (Expression, error) {
 	var currExpr Expression
 
@@ -819,11 +2299,23 @@ func (p *Parser) parsePrimary() (Expression, error) {
 	case FALSE:
 		currExpr = &LiteralExpr{Literal: false, Line: token.Line}
 	case NIL:
-		currExpr = &LiteralExpr{Literal: &NilExpr{}, Line: token.Line}
+		currExpr = &NilExpr{}
 	case NUMBER, STRING:
 		currExpr = &LiteralExpr{Literal: token.Literal, Line: token.Line}
-	case IDENTIFIER, THIS, SUPER:
+	case IDENTIFIER, THIS:
 		currExpr = &IdentifierExpr{Name: token.Lexeme, Line: token.Line}
+	case SUPER:
+		_, err := p.match(DOT)
+		if err != nil {
+			return nil, fmt.Errorf("[line %d] Error at 'super': Expect '.' after 'super'.", token.Line)
+		}
+
+		method, err := p.match(IDENTIFIER)
+		if err != nil {
+			return nil, fmt.Errorf("[line %d] Error at 'super': Expect superclass method name.", token.Line)
+		}
+
+		currExpr = &SuperExpr{Method: method.Lexeme, Line: token.Line}
 	case LEFT_PAREN:
 		e, err := p.parseExpression()
 		if err != nil {
@@ -840,6 +2332,48 @@ func (p *Parser) parsePrimary() (Expression, error) {
 		}
 
 		currExpr = &GroupingExpr{Expr: e, Line: token.Line}
+	case MATCH:
+		subject, cases, err := p.parseMatch(func(c *MatchCase) error {
+			e, err := p.parseExpression()
+			if err != nil {
+				return err
+			}
+
+			_, err = p.match(SEMICOLON)
+			if err != nil {
+				return err
+			}
+
+			c.Expr = e
+
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		currExpr = &MatchExpr{Subject: subject, Cases: cases, Line: token.Line}
+	case LEFT_BRACKET:
+		var elements []Expression
+
+		_, err := p.match(RIGHT_BRACKET)
+		if err != nil {
+			if errors.Is(err, ErrUnexpectedEOF) {
+				return nil, err
+			}
+
+			elements, err = p.parseArguments()
+			if err != nil {
+				return nil, err
+			}
+
+			_, err = p.match(RIGHT_BRACKET)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		currExpr = &ListExpr{Elements: elements, Line: token.Line}
 	default:
 		return nil, fmt.Errorf("[line %d] Error at '%s': Expect expression.", token.Line, token.Lexeme)
 	}
@@ -847,6 +2381,30 @@ func (p *Parser) parsePrimary() (Expression, error) {
 	return currExpr, nil
 }
 
+func (p *Parser) beginScope() {
+	p.scopes = append(p.scopes, map[string]bool{})
+}
+
+func (p *Parser) endScope() {
+	p.scopes = p.scopes[:len(p.scopes)-1]
+}
+
+func (p *Parser) declare(name string, isConst bool) {
+	p.scopes[len(p.scopes)-1][name] = isConst
+}
+
+// isConstant reports whether name resolves to a constant in the enclosing scopes, as
+// long as no declaration which follows can change what it resolves to.
+func (p *Parser) isConstant(name string) bool {
+	for i := len(p.scopes) - 1; i >= p.function; i-- {
+		if isConst, ok := p.scopes[i][name]; ok {
+			return isConst
+		}
+	}
+
+	return false
+}
+
 func (p *Parser) nextToken() (*Token, bool) {
 	p.pos++
 
@@ -881,6 +2439,16 @@ func (p *Parser) peek() (*Token, bool) {
 	return p.tokens[p.pos+1], true
 }
 
+// peekAt returns the token n positions ahead without consuming anything, peekAt(1) is
+// the same as peek.
+func (p *Parser) peekAt(n int) (*Token, bool) {
+	if p.pos+n >= len(p.tokens) {
+		return nil, false
+	}
+
+	return p.tokens[p.pos+n], true
+}
+
 func (p *Parser) goBack(amount int) {
 	if p.pos-amount <= -1 {
 		p.pos = -1
@@ -0,0 +1,98 @@
+package main
+
+import "testing"
+
+func TestConstAssignment(t *testing.T) {
+	runProgramTests(t, []programTest{
+		{
+			name:    "assignment to a constant",
+			source:  "const x = 1;\nx = 2;",
+			wantErr: "[line 2] Error at 'x': Cannot assign to constant 'x'.",
+		},
+		{
+			name:    "assignment in a nested block",
+			source:  "const x = 1;\n{\n  x = 2;\n  var x = 3;\n}",
+			wantErr: "[line 3] Error at 'x': Cannot assign to constant 'x'.",
+		},
+		{
+			name:    "assignment to a constant of the same function",
+			source:  "fun f() {\n  const x = 1;\n  {\n    x += 1;\n  }\n}",
+			wantErr: "[line 4] Error at 'x': Cannot assign to constant 'x'.",
+		},
+		{
+			name:   "redeclared as a variable",
+			source: "const x = 1;\nvar x = 2;\nx = 3;\nprint x;",
+			want:   "3\n",
+		},
+		{
+			name:    "assignment from a function is checked when it runs",
+			source:  "const x = 1;\nfun g() {\n  x = 3;\n}\ng();",
+			wantErr: "Cannot assign to constant 'x'.\n[line 3]",
+		},
+		{
+			name: "shadowed after the function using it",
+			source: `
+const x = 1;
+{
+  fun g() { x = 3; print x; }
+  var x = 2;
+  g();
+}
+print x;
+`,
+			want: "3\n1\n",
+		},
+		{
+			name: "shadowed after the getter using it",
+			source: `
+const x = 1;
+{
+  class A { value { x = 3; return x; } }
+  var x = 2;
+  print A().value;
+}
+`,
+			want: "3\n",
+		},
+	})
+}
+
+func TestParameters(t *testing.T) {
+	runProgramTests(t, []programTest{
+		{
+			name:    "duplicate parameter",
+			source:  "fun f(a, a) {}",
+			wantErr: "[line 1] Error at 'a': Function already has a parameter called a.",
+		},
+		{
+			name:    "duplicate default parameter",
+			source:  "fun f(a, b = 1, b = 2) {}",
+			wantErr: "[line 1] Error at 'b': Function already has a parameter called b.",
+		},
+		{
+			name:    "rest parameter named like another one",
+			source:  "fun f(a = 1, ...a) {}",
+			wantErr: "[line 1] Error at 'a': Function already has a parameter called a.",
+		},
+		{
+			name:    "duplicate method parameter",
+			source:  "class A {\n  m(x, x) {}\n}",
+			wantErr: "[line 2] Error at 'x': Function already has a parameter called x.",
+		},
+		{
+			name:   "default and rest parameters",
+			source: "fun f(a, b = 2, ...rest) { print [a, b, rest]; }\nf(1);\nf(1, 3, 4, 5);",
+			want:   "[1, 2, []]\n[1, 3, [4, 5]]\n",
+		},
+		{
+			name:    "too few arguments for a rest parameter",
+			source:  "fun f(a, ...rest) {}\nf();",
+			wantErr: "Expected at least 1 argument but got 0.\n[line 2]",
+		},
+		{
+			name:    "too many arguments for a default parameter",
+			source:  "fun f(a, b = 1) {}\nf(1, 2, 3);",
+			wantErr: "Expected 1 to 2 arguments but got 3.\n[line 2]",
+		},
+	})
+}
@@ -0,0 +1,316 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+)
+
+// typeName is the name typeof() returns for a runtime value.
+func typeName(val interface{}) string {
+	switch val.(type) {
+	case nil:
+		return "nil"
+	case bool:
+		return "bool"
+	case int64, float64:
+		return "number"
+	case *big.Int:
+		return "bigint"
+	case *big.Rat:
+		return "decimal"
+	case string:
+		return "string"
+	case *List:
+		return "list"
+	case *Range:
+		return "range"
+	case *Generator:
+		return "generator"
+	case *Task:
+		return "task"
+	case *Channel:
+		return "channel"
+	case *Enum:
+		return "enum"
+	case *EnumValue:
+		return "enum value"
+	case *ClassCaller:
+		return "class"
+	case *ClassInstance:
+		return "instance"
+	case *Trait:
+		return "trait"
+	case *Interface:
+		return "interface"
+	case Caller:
+		return "function"
+	}
+
+	return "unknown"
+}
+
+// isInstance implements the "is" operator: whether val is an instance of a class, of
+// one of its subclasses, or of a class composed with a trait or declaring to
+// implement an interface.
+func isInstance(val interface{}, target interface{}) (bool, error) {
+	switch target.(type) {
+	case *ClassCaller, *Trait, *Interface:
+	default:
+		return false, errors.New("Right operand of 'is' must be a class, a trait or an interface.")
+	}
+
+	ci, ok := val.(*ClassInstance)
+	if !ok {
+		return false, nil
+	}
+
+	for curr := ci.Class; curr != nil; curr = curr.SuperClass {
+		if curr == target {
+			return true, nil
+		}
+
+		for _, t := range curr.Traits {
+			if t == target {
+				return true, nil
+			}
+		}
+
+		for _, i := range curr.Interfaces {
+			if i == target {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
+func namesList(names []string) *List {
+	slices.Sort(names)
+
+	l := List{Elements: make([]interface{}, 0, len(names))}
+	for _, name := range names {
+		l.Elements = append(l.Elements, name)
+	}
+
+	return &l
+}
+
+// fieldsOf returns the map holding the fields of an instance, or the static fields of
+// a class.
+func fieldsOf(val interface{}, function string) (map[string]interface{}, error) {
+	switch v := val.(type) {
+	case *ClassInstance:
+		return v.Properties, nil
+	case *ClassCaller:
+		return v.StaticFields, nil
+	}
+
+	return nil, fmt.Errorf("%s() expects an instance or a class.", function)
+}
+
+// fieldName returns the field name given to a reflection builtin. Private members are
+// only accessible through "this", so their names are rejected.
+func fieldName(arg interface{}, function string) (string, error) {
+	name, ok := arg.(string)
+	if !ok {
+		return "", fmt.Errorf("%s() expects a field name.", function)
+	}
+
+	if isPrivate(name) {
+		return "", fmt.Errorf("%s() cannot access the private member %s.", function, name)
+	}
+
+	return name, nil
+}
+
+// NativeTypeOf returns the name of the type of a value.
+type NativeTypeOf struct{}
+
+func (nt *NativeTypeOf) Call(args ...interface{}) (interface{}, error) {
+	return typeName(args[0]), nil
+}
+
+func (nt *NativeTypeOf) Arity() (int, int) { return 1, 1 }
+
+func (nt *NativeTypeOf) String() string {
+	return "<native fn>"
+}
+
+// NativeFields lists the field names of an instance, or the static fields of a class.
+type NativeFields struct{}
+
+func (nf *NativeFields) Call(args ...interface{}) (interface{}, error) {
+	fields, err := fieldsOf(args[0], "fields")
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for name := range fields {
+		names = append(names, name)
+	}
+
+	return namesList(names), nil
+}
+
+func (nf *NativeFields) Arity() (int, int) { return 1, 1 }
+
+func (nf *NativeFields) String() string {
+	return "<native fn>"
+}
+
+// NativeMethods lists the names of the methods of a class, or of the class of an
+// instance, including inherited ones.
+type NativeMethods struct{}
+
+func (nm *NativeMethods) Call(args ...interface{}) (interface{}, error) {
+	cc, ok := args[0].(*ClassCaller)
+	if ci, isInstance := args[0].(*ClassInstance); isInstance {
+		cc, ok = ci.Class, true
+	}
+
+	if !ok {
+		return nil, errors.New("methods() expects a class or an instance.")
+	}
+
+	var names []string
+	for curr := cc; curr != nil; curr = curr.SuperClass {
+		for name := range curr.Methods {
+			if !isPrivate(name) && !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+
+	return namesList(names), nil
+}
+
+func (nm *NativeMethods) Arity() (int, int) { return 1, 1 }
+
+func (nm *NativeMethods) String() string {
+	return "<native fn>"
+}
+
+// NativeHasField reports whether an instance, or a class for static fields, has a
+// field called name.
+type NativeHasField struct{}
+
+func (nh *NativeHasField) Call(args ...interface{}) (interface{}, error) {
+	fields, err := fieldsOf(args[0], "hasField")
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := fieldName(args[1], "hasField")
+	if err != nil {
+		return nil, err
+	}
+
+	_, found := fields[name]
+
+	return found, nil
+}
+
+func (nh *NativeHasField) Arity() (int, int) { return 2, 2 }
+
+func (nh *NativeHasField) String() string {
+	return "<native fn>"
+}
+
+// NativeGetField reads a field of an instance, or a static field of a class, by name.
+type NativeGetField struct{}
+
+func (ng *NativeGetField) Call(args ...interface{}) (interface{}, error) {
+	fields, err := fieldsOf(args[0], "getField")
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := fieldName(args[1], "getField")
+	if err != nil {
+		return nil, err
+	}
+
+	val, found := fields[name]
+	if !found {
+		s, _ := Stringify(args[0])
+		return nil, fmt.Errorf("%s has no field called %s.", s, name)
+	}
+
+	return val, nil
+}
+
+func (ng *NativeGetField) Arity() (int, int) { return 2, 2 }
+
+func (ng *NativeGetField) String() string {
+	return "<native fn>"
+}
+
+// NativeSetField writes a field of an instance, or a static field of a class, by name
+// and returns the value written. It follows the rules of assignments, so setters are
+// called and methods cannot be overwritten.
+type NativeSetField struct{}
+
+func (ns *NativeSetField) Call(args ...interface{}) (interface{}, error) {
+	_, err := fieldsOf(args[0], "setField")
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := fieldName(args[1], "setField")
+	if err != nil {
+		return nil, err
+	}
+
+	if obj, ok := args[0].(*ClassInstance); ok && obj.Frozen {
+		return nil, fmt.Errorf("Invalid operation, object %s is frozen", obj.Class.Name)
+	}
+
+	setter, fields, err := fieldAssignment(args[0], name)
+	if err != nil {
+		return nil, err
+	}
+
+	if setter != nil {
+		_, err := setter.Call(args[2])
+		if err != nil {
+			return nil, err
+		}
+
+		return args[2], nil
+	}
+
+	fields[name] = args[2]
+
+	return args[2], nil
+}
+
+func (ns *NativeSetField) Arity() (int, int) { return 3, 3 }
+
+func (ns *NativeSetField) String() string {
+	return "<native fn>"
+}
+
+// NativeFreeze makes an instance reject any further change to its fields and returns
+// it.
+type NativeFreeze struct{}
+
+func (nf *NativeFreeze) Call(args ...interface{}) (interface{}, error) {
+	obj, ok := args[0].(*ClassInstance)
+	if !ok {
+		return nil, errors.New("freeze() expects an instance.")
+	}
+
+	obj.Frozen = true
+
+	return obj, nil
+}
+
+func (nf *NativeFreeze) Arity() (int, int) { return 1, 1 }
+
+func (nf *NativeFreeze) String() string {
+	return "<native fn>"
+}
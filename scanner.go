@@ -3,7 +3,10 @@ package main
 import (
 	"errors"
 	"fmt"
+	"math"
+	"math/big"
 	"strconv"
+	"strings"
 )
 
 type TokenType string
@@ -11,68 +14,111 @@ type TokenType string
 func (t TokenType) Is(t2 TokenType) bool { return t == t2 }
 
 const (
-	LEFT_PAREN    TokenType = "("
-	RIGHT_PAREN   TokenType = ")"
-	LEFT_BRACE    TokenType = "{"
-	RIGHT_BRACE   TokenType = "}"
-	COMMA         TokenType = ","
-	DOT           TokenType = "."
-	SEMICOLON     TokenType = ";"
-	PLUS          TokenType = "+"
-	MINUS         TokenType = "-"
-	STAR          TokenType = "*"
-	EQUAL         TokenType = "="
-	EQUAL_EQUAL   TokenType = "=="
-	BANG          TokenType = "!"
-	BANG_EQUAL    TokenType = "!="
-	LESS          TokenType = "<"
-	LESS_EQUAL    TokenType = "<="
-	GREATER       TokenType = ">"
-	GREATER_EQUAL TokenType = ">="
-	SLASH         TokenType = "/"
-	NEWLINE       TokenType = "\n"
-	SPACE         TokenType = " "
-	TAB           TokenType = "\t"
-	STRING        TokenType = "<str>"
-	NUMBER        TokenType = "num>"
-	QUOTE         TokenType = "\""
-	IDENTIFIER    TokenType = "<identifier>"
-	AND           TokenType = "and"
-	CLASS         TokenType = "class"
-	ELSE          TokenType = "else"
-	FALSE         TokenType = "false"
-	FOR           TokenType = "for"
-	FUN           TokenType = "fun"
-	IF            TokenType = "if"
-	NIL           TokenType = "nil"
-	OR            TokenType = "or"
-	PRINT         TokenType = "print"
-	RETURN        TokenType = "return"
-	SUPER         TokenType = "super"
-	THIS          TokenType = "this"
-	TRUE          TokenType = "true"
-	VAR           TokenType = "var"
-	WHILE         TokenType = "while"
-	EOF           TokenType = ""
+	LEFT_PAREN        TokenType = "("
+	RIGHT_PAREN       TokenType = ")"
+	LEFT_BRACE        TokenType = "{"
+	RIGHT_BRACE       TokenType = "}"
+	LEFT_BRACKET      TokenType = "["
+	RIGHT_BRACKET     TokenType = "]"
+	COMMA             TokenType = ","
+	DOT               TokenType = "."
+	ELLIPSIS          TokenType = "..."
+	SEMICOLON         TokenType = ";"
+	COLON             TokenType = ":"
+	QUESTION          TokenType = "?"
+	QUESTION_QUESTION TokenType = "??"
+	QUESTION_DOT      TokenType = "?."
+	PLUS              TokenType = "+"
+	MINUS             TokenType = "-"
+	STAR              TokenType = "*"
+	EQUAL             TokenType = "="
+	EQUAL_EQUAL       TokenType = "=="
+	FAT_ARROW         TokenType = "=>"
+	BANG              TokenType = "!"
+	BANG_EQUAL        TokenType = "!="
+	LESS              TokenType = "<"
+	LESS_EQUAL        TokenType = "<="
+	GREATER           TokenType = ">"
+	GREATER_EQUAL     TokenType = ">="
+	SLASH             TokenType = "/"
+	PERCENT           TokenType = "%"
+	PLUS_EQUAL        TokenType = "+="
+	MINUS_EQUAL       TokenType = "-="
+	STAR_EQUAL        TokenType = "*="
+	SLASH_EQUAL       TokenType = "/="
+	PERCENT_EQUAL     TokenType = "%="
+	PLUS_PLUS         TokenType = "++"
+	MINUS_MINUS       TokenType = "--"
+	NEWLINE           TokenType = "\n"
+	SPACE             TokenType = " "
+	TAB               TokenType = "\t"
+	STRING            TokenType = "<str>"
+	NUMBER            TokenType = "num>"
+	QUOTE             TokenType = "\""
+	HASH              TokenType = "#"
+	IDENTIFIER        TokenType = "<identifier>"
+	AND               TokenType = "and"
+	CLASS             TokenType = "class"
+	CONST             TokenType = "const"
+	ELSE              TokenType = "else"
+	FALSE             TokenType = "false"
+	FOR               TokenType = "for"
+	FUN               TokenType = "fun"
+	IF                TokenType = "if"
+	INTERFACE         TokenType = "interface"
+	IS                TokenType = "is"
+	IN                TokenType = "in"
+	MATCH             TokenType = "match"
+	CASE              TokenType = "case"
+	NIL               TokenType = "nil"
+	OR                TokenType = "or"
+	PRINT             TokenType = "print"
+	RETURN            TokenType = "return"
+	STATIC            TokenType = "static"
+	SUPER             TokenType = "super"
+	THIS              TokenType = "this"
+	TRAIT             TokenType = "trait"
+	TRUE              TokenType = "true"
+	VAR               TokenType = "var"
+	WHILE             TokenType = "while"
+	YIELD             TokenType = "yield"
+	SPAWN             TokenType = "spawn"
+	AWAIT             TokenType = "await"
+	SELECT            TokenType = "select"
+	ENUM              TokenType = "enum"
+	EOF               TokenType = ""
 )
 
 var reservedWords = map[TokenType]struct{}{
-	AND:    {},
-	CLASS:  {},
-	ELSE:   {},
-	FALSE:  {},
-	FOR:    {},
-	FUN:    {},
-	IF:     {},
-	NIL:    {},
-	OR:     {},
-	PRINT:  {},
-	RETURN: {},
-	SUPER:  {},
-	THIS:   {},
-	TRUE:   {},
-	VAR:    {},
-	WHILE:  {},
+	AND:       {},
+	CLASS:     {},
+	CONST:     {},
+	ELSE:      {},
+	FALSE:     {},
+	FOR:       {},
+	FUN:       {},
+	IF:        {},
+	INTERFACE: {},
+	IS:        {},
+	IN:        {},
+	MATCH:     {},
+	CASE:      {},
+	NIL:       {},
+	OR:        {},
+	PRINT:     {},
+	RETURN:    {},
+	STATIC:    {},
+	SUPER:     {},
+	THIS:      {},
+	TRAIT:     {},
+	TRUE:      {},
+	VAR:       {},
+	WHILE:     {},
+	YIELD:     {},
+	SPAWN:     {},
+	AWAIT:     {},
+	SELECT:    {},
+	ENUM:      {},
 }
 
 func (t TokenType) Type() string {
@@ -85,6 +131,10 @@ func (t TokenType) Type() string {
 		return "LEFT_BRACE"
 	case RIGHT_BRACE:
 		return "RIGHT_BRACE"
+	case LEFT_BRACKET:
+		return "LEFT_BRACKET"
+	case RIGHT_BRACKET:
+		return "RIGHT_BRACKET"
 	case COMMA:
 		return "COMMA"
 	case PLUS:
@@ -93,14 +143,26 @@ func (t TokenType) Type() string {
 		return "MINUS"
 	case DOT:
 		return "DOT"
+	case ELLIPSIS:
+		return "ELLIPSIS"
 	case SEMICOLON:
 		return "SEMICOLON"
+	case COLON:
+		return "COLON"
+	case QUESTION:
+		return "QUESTION"
+	case QUESTION_QUESTION:
+		return "QUESTION_QUESTION"
+	case QUESTION_DOT:
+		return "QUESTION_DOT"
 	case STAR:
 		return "STAR"
 	case EQUAL:
 		return "EQUAL"
 	case EQUAL_EQUAL:
 		return "EQUAL_EQUAL"
+	case FAT_ARROW:
+		return "FAT_ARROW"
 	case BANG:
 		return "BANG"
 	case BANG_EQUAL:
@@ -115,6 +177,22 @@ func (t TokenType) Type() string {
 		return "GREATER_EQUAL"
 	case SLASH:
 		return "SLASH"
+	case PERCENT:
+		return "PERCENT"
+	case PLUS_EQUAL:
+		return "PLUS_EQUAL"
+	case MINUS_EQUAL:
+		return "MINUS_EQUAL"
+	case STAR_EQUAL:
+		return "STAR_EQUAL"
+	case SLASH_EQUAL:
+		return "SLASH_EQUAL"
+	case PERCENT_EQUAL:
+		return "PERCENT_EQUAL"
+	case PLUS_PLUS:
+		return "PLUS_PLUS"
+	case MINUS_MINUS:
+		return "MINUS_MINUS"
 	case NEWLINE:
 		return "NEWLINE"
 	case SPACE:
@@ -133,6 +211,8 @@ func (t TokenType) Type() string {
 		return "AND"
 	case CLASS:
 		return "CLASS"
+	case CONST:
+		return "CONST"
 	case ELSE:
 		return "ELSE"
 	case FALSE:
@@ -143,6 +223,16 @@ func (t TokenType) Type() string {
 		return "FUN"
 	case IF:
 		return "IF"
+	case INTERFACE:
+		return "INTERFACE"
+	case IS:
+		return "IS"
+	case IN:
+		return "IN"
+	case MATCH:
+		return "MATCH"
+	case CASE:
+		return "CASE"
 	case NIL:
 		return "NIL"
 	case OR:
@@ -151,16 +241,30 @@ func (t TokenType) Type() string {
 		return "PRINT"
 	case RETURN:
 		return "RETURN"
+	case STATIC:
+		return "STATIC"
 	case SUPER:
 		return "SUPER"
 	case THIS:
 		return "THIS"
+	case TRAIT:
+		return "TRAIT"
 	case TRUE:
 		return "TRUE"
 	case VAR:
 		return "VAR"
 	case WHILE:
 		return "WHILE"
+	case YIELD:
+		return "YIELD"
+	case SPAWN:
+		return "SPAWN"
+	case AWAIT:
+		return "AWAIT"
+	case SELECT:
+		return "SELECT"
+	case ENUM:
+		return "ENUM"
 	default:
 		return ""
 	}
@@ -209,16 +313,41 @@ func (s *Scanner) NextToken() (*Token, error) {
 			}
 
 			s.done = true
+		case TokenType(currChar).Is(DOT) && s.hasPrefix(ELLIPSIS):
+			currToken = Token{
+				Type:    ELLIPSIS,
+				Lexeme:  string(ELLIPSIS),
+				Literal: nil,
+				Line:    s.lineNum,
+			}
+
+			s.pos += len(ELLIPSIS) - 1
+		case TokenType(currChar).Is(QUESTION):
+			tokenType := QUESTION
+			if s.hasPrefix(QUESTION_QUESTION) {
+				tokenType = QUESTION_QUESTION
+			} else if s.hasPrefix(QUESTION_DOT) {
+				tokenType = QUESTION_DOT
+			}
+
+			currToken = Token{
+				Type:    tokenType,
+				Lexeme:  string(tokenType),
+				Literal: nil,
+				Line:    s.lineNum,
+			}
+
+			s.pos += len(tokenType) - 1
 		case TokenType(currChar).Is(LEFT_PAREN) ||
 			TokenType(currChar).Is(RIGHT_PAREN) ||
 			TokenType(currChar).Is(LEFT_BRACE) ||
 			TokenType(currChar).Is(RIGHT_BRACE) ||
+			TokenType(currChar).Is(LEFT_BRACKET) ||
+			TokenType(currChar).Is(RIGHT_BRACKET) ||
 			TokenType(currChar).Is(COMMA) ||
 			TokenType(currChar).Is(DOT) ||
 			TokenType(currChar).Is(SEMICOLON) ||
-			TokenType(currChar).Is(PLUS) ||
-			TokenType(currChar).Is(MINUS) ||
-			TokenType(currChar).Is(STAR):
+			TokenType(currChar).Is(COLON):
 			currToken = Token{
 				Type:    TokenType(currChar),
 				Lexeme:  string(currChar),
@@ -241,6 +370,18 @@ func (s *Scanner) NextToken() (*Token, error) {
 				break
 			}
 
+			if nextChar, exist := s.peek(); exist && TokenType(nextChar).Is(GREATER) {
+				currToken = Token{
+					Type:    FAT_ARROW,
+					Lexeme:  string(FAT_ARROW),
+					Literal: nil,
+					Line:    s.lineNum,
+				}
+
+				s.nextChar()
+				break
+			}
+
 			currToken = Token{
 				Type:    EQUAL,
 				Lexeme:  string(EQUAL),
@@ -304,6 +445,27 @@ func (s *Scanner) NextToken() (*Token, error) {
 				Literal: "null",
 				Line:    s.lineNum,
 			}
+		case TokenType(currChar).Is(PLUS) ||
+			TokenType(currChar).Is(MINUS) ||
+			TokenType(currChar).Is(STAR) ||
+			TokenType(currChar).Is(PERCENT) ||
+			(TokenType(currChar).Is(SLASH) && s.hasPrefix(SLASH_EQUAL)):
+			tokenType := TokenType(currChar)
+			for _, t := range compoundOperators[tokenType] {
+				if s.hasPrefix(t) {
+					tokenType = t
+					break
+				}
+			}
+
+			currToken = Token{
+				Type:    tokenType,
+				Lexeme:  string(tokenType),
+				Literal: nil,
+				Line:    s.lineNum,
+			}
+
+			s.pos += len(tokenType) - 1
 		case TokenType(currChar).Is(SLASH):
 			if nextChar, exist := s.peek(); exist && TokenType(nextChar).Is(SLASH) {
 				// comment encountered
@@ -362,53 +524,235 @@ func (s *Scanner) NextToken() (*Token, error) {
 				Line:   s.lineNum,
 			}
 
-			for {
-				n, e := s.peek()
-				if !e || (!isNumeric(n) && !TokenType(n).Is(DOT)) {
-					break
-				}
-
-				currToken.Lexeme += string(n)
-
-				s.nextChar()
-			}
-
-			num, err := strconv.ParseFloat(currToken.Lexeme, 64)
+			num, err := s.scanNumber(&currToken)
 			if err != nil {
-
 				return nil, err
 			}
 
 			currToken.Literal = num
-		case isAlphabet(currChar) || currChar == '_':
-			currToken = Token{
-				Type:    IDENTIFIER,
-				Literal: nil,
-				Lexeme:  string(currChar),
-				Line:    s.lineNum,
+		case TokenType(currChar).Is(HASH):
+			// "#name" is the identifier of a private class member
+			n, e := s.peek()
+			if !e || (!isAlphabet(n) && n != '_') {
+				errStr := fmt.Sprintf("[line %d] Error: Unexpected character: ", s.lineNum) + string(currChar)
+				return nil, errors.New(errStr)
 			}
 
-			for {
-				n, e := s.peek()
-				if !e || (!isAlphaNumeric(n) && n != '_') {
-					break
-				}
+			s.nextChar()
+			currToken = s.scanIdentifier(string(currChar) + string(n))
+		case isAlphabet(currChar) || currChar == '_':
+			currToken = s.scanIdentifier(string(currChar))
+		default:
+			errStr := fmt.Sprintf("[line %d] Error: Unexpected character: ", s.lineNum) + string(currChar)
+			return nil, errors.New(errStr)
+		}
+
+		return &currToken, nil
+	}
+}
 
-				currToken.Lexeme += string(n)
+// parseNumber parses str when all of it is a single number literal.
+func parseNumber(str string) (interface{}, bool) {
+	token, err := NewScanner([]byte(str)).NextToken()
+	if err != nil || !token.Type.Is(NUMBER) || token.Lexeme != str {
+		return nil, false
+	}
 
-				s.nextChar()
+	return token.Literal, true
+}
+
+// scanNumber consumes the rest of a number literal whose first digit is already in
+// token.Lexeme. Supported forms are decimals with an optional fraction and exponent
+// (1.5e10), hex (0xFF), binary (0b1010) and octal (0o17), all of which may use '_'
+// as a digit separator (1_000_000). Literals without a fraction or exponent produce an
+// int64, unless they do not fit in one, or a BigInt when suffixed with 'n' (10n).
+func (s *Scanner) scanNumber(token *Token) (interface{}, error) {
+	if token.Lexeme == "0" {
+		if n, e := s.peek(); e {
+			var base int
+			var isDigit func(byte) bool
+
+			switch n {
+			case 'x', 'X':
+				base, isDigit = 16, isHexDigit
+			case 'b', 'B':
+				base, isDigit = 2, isBinaryDigit
+			case 'o', 'O':
+				base, isDigit = 8, isOctalDigit
 			}
 
-			if _, isKeyword := reservedWords[TokenType(currToken.Lexeme)]; isKeyword {
-				currToken.Type = TokenType(currToken.Lexeme)
+			if base != 0 {
+				token.Lexeme += string(n)
+				s.nextChar()
+
+				return s.scanRadixNumber(token, base, isDigit)
 			}
-		default:
-			errStr := fmt.Sprintf("[line %d] Error: Unexpected character: ", s.lineNum) + string(currChar)
-			return nil, errors.New(errStr)
 		}
+	}
 
-		return &currToken, nil
+	s.scanDigits(token, isNumeric)
+
+	isFloat := false
+
+	if n, e := s.peek(); e && TokenType(n).Is(DOT) {
+		isFloat = true
+		token.Lexeme += string(n)
+		s.nextChar()
+
+		if n, e := s.peek(); !e || !isNumeric(n) {
+			return 0, fmt.Errorf("[line %d] Error: Invalid number '%s': expected digits after '.'.", token.Line, token.Lexeme)
+		}
+
+		s.scanDigits(token, isNumeric)
+
+		if n, e := s.peek(); e && TokenType(n).Is(DOT) {
+			s.scanDigits(token, func(b byte) bool { return isNumeric(b) || TokenType(b).Is(DOT) })
+			return 0, fmt.Errorf("[line %d] Error: Invalid number '%s': too many decimal points.", token.Line, token.Lexeme)
+		}
+	}
+
+	if n, e := s.peek(); e && (n == 'e' || n == 'E') {
+		isFloat = true
+		token.Lexeme += string(n)
+		s.nextChar()
+
+		if n, e := s.peek(); e && (n == '+' || n == '-') {
+			token.Lexeme += string(n)
+			s.nextChar()
+		}
+
+		if n, e := s.peek(); !e || !isNumeric(n) {
+			return 0, fmt.Errorf("[line %d] Error: Invalid number '%s': expected digits in exponent.", token.Line, token.Lexeme)
+		}
+
+		s.scanDigits(token, isNumeric)
+	}
+
+	if err := checkDigitSeparators(token, token.Lexeme, isNumeric); err != nil {
+		return 0, err
 	}
+
+	digits := strings.ReplaceAll(token.Lexeme, "_", "")
+
+	if n, e := s.peek(); e && n == 'n' {
+		token.Lexeme += string(n)
+		s.nextChar()
+
+		if isFloat {
+			return 0, fmt.Errorf("[line %d] Error: Invalid number '%s': BigInt literals must be integers.", token.Line, token.Lexeme)
+		}
+
+		num, _ := new(big.Int).SetString(digits, 10)
+		return num, nil
+	}
+
+	if !isFloat {
+		if num, err := strconv.ParseInt(digits, 10, 64); err == nil {
+			return num, nil
+		}
+	}
+
+	num, err := strconv.ParseFloat(digits, 64)
+	if err != nil {
+		return 0, fmt.Errorf("[line %d] Error: Number '%s' is out of range.", token.Line, token.Lexeme)
+	}
+
+	return num, nil
+}
+
+func (s *Scanner) scanRadixNumber(token *Token, base int, isDigit func(byte) bool) (interface{}, error) {
+	prefixLen := len(token.Lexeme)
+
+	// scan every alphanumeric character so that "0b102" is reported as a single bad literal
+	s.scanDigits(token, isAlphaNumeric)
+
+	digits, isBigInt := strings.CutSuffix(token.Lexeme[prefixLen:], "n")
+	if len(digits) == 0 {
+		return 0, fmt.Errorf("[line %d] Error: Invalid number '%s': expected digits after '%s'.", token.Line, token.Lexeme, token.Lexeme)
+	}
+
+	for i := 0; i < len(digits); i++ {
+		if digits[i] != '_' && !isDigit(digits[i]) {
+			return 0, fmt.Errorf("[line %d] Error: Invalid digit '%c' in number '%s'.", token.Line, digits[i], token.Lexeme)
+		}
+	}
+
+	if err := checkDigitSeparators(token, digits, isDigit); err != nil {
+		return 0, err
+	}
+
+	if isBigInt {
+		num, _ := new(big.Int).SetString(strings.ReplaceAll(digits, "_", ""), base)
+		return num, nil
+	}
+
+	num, err := strconv.ParseUint(strings.ReplaceAll(digits, "_", ""), base, 64)
+	if err != nil {
+		return 0, fmt.Errorf("[line %d] Error: Number '%s' is out of range.", token.Line, token.Lexeme)
+	}
+
+	if num > math.MaxInt64 {
+		return float64(num), nil
+	}
+
+	return int64(num), nil
+}
+
+// scanDigits appends to token.Lexeme every following character accepted by isDigit or
+// being a '_' separator.
+func (s *Scanner) scanDigits(token *Token, isDigit func(byte) bool) {
+	for {
+		n, e := s.peek()
+		if !e || (!isDigit(n) && n != '_') {
+			break
+		}
+
+		token.Lexeme += string(n)
+
+		s.nextChar()
+	}
+}
+
+// checkDigitSeparators makes sure every '_' in digits sits between two digits.
+func checkDigitSeparators(token *Token, digits string, isDigit func(byte) bool) error {
+	for i := 0; i < len(digits); i++ {
+		if digits[i] != '_' {
+			continue
+		}
+
+		if i == 0 || i == len(digits)-1 || !isDigit(digits[i-1]) || !isDigit(digits[i+1]) {
+			return fmt.Errorf("[line %d] Error: Invalid number '%s': '_' must separate digits.", token.Line, token.Lexeme)
+		}
+	}
+
+	return nil
+}
+
+// scanIdentifier consumes the rest of an identifier or a keyword starting with lexeme.
+func (s *Scanner) scanIdentifier(lexeme string) Token {
+	token := Token{
+		Type:    IDENTIFIER,
+		Literal: nil,
+		Lexeme:  lexeme,
+		Line:    s.lineNum,
+	}
+
+	for {
+		n, e := s.peek()
+		if !e || (!isAlphaNumeric(n) && n != '_') {
+			break
+		}
+
+		token.Lexeme += string(n)
+
+		s.nextChar()
+	}
+
+	if _, isKeyword := reservedWords[TokenType(token.Lexeme)]; isKeyword {
+		token.Type = TokenType(token.Lexeme)
+	}
+
+	return token
 }
 
 func (s *Scanner) HasNext() bool {
@@ -428,6 +772,22 @@ func (s *Scanner) nextChar() (byte, bool) {
 	return c, true
 }
 
+// compoundOperators maps the arithmetic operators to the longer tokens starting with
+// them.
+var compoundOperators = map[TokenType][]TokenType{
+	PLUS:    {PLUS_PLUS, PLUS_EQUAL},
+	MINUS:   {MINUS_MINUS, MINUS_EQUAL},
+	STAR:    {STAR_EQUAL},
+	SLASH:   {SLASH_EQUAL},
+	PERCENT: {PERCENT_EQUAL},
+}
+
+// hasPrefix reports whether the content starting at the current character begins with
+// the lexeme of tokenType.
+func (s *Scanner) hasPrefix(tokenType TokenType) bool {
+	return strings.HasPrefix(string(s.content[s.pos:]), string(tokenType))
+}
+
 func (s *Scanner) peek() (byte, bool) {
 	if s.pos+1 >= len(s.content) {
 		return 0, false
@@ -440,6 +800,18 @@ func isNumeric(b byte) bool {
 	return b >= '0' && b <= '9'
 }
 
+func isHexDigit(b byte) bool {
+	return isNumeric(b) || (b >= 'a' && b <= 'f') || (b >= 'A' && b <= 'F')
+}
+
+func isBinaryDigit(b byte) bool {
+	return b == '0' || b == '1'
+}
+
+func isOctalDigit(b byte) bool {
+	return b >= '0' && b <= '7'
+}
+
 func isAlphabet(b byte) bool {
 	return (b >= 'A' && b <= 'Z') || (b >= 'a' && b <= 'z')
 }
@@ -453,10 +825,8 @@ func stringifyNumOrNil(token *Token) string {
 		return "null"
 	}
 
-	if v, ok := token.Literal.(float64); ok {
-		if v == float64(int64(v)) {
-			return fmt.Sprintf("%.1f", v)
-		}
+	if isNumber(token.Literal) {
+		return formatNumberLiteral(token.Literal)
 	}
 
 	return fmt.Sprintf("%v", token.Literal)
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestScanNumber(t *testing.T) {
+	tests := []struct {
+		source  string
+		want    interface{}
+		wantErr string
+	}{
+		{source: "42", want: int64(42)},
+		{source: "1.5", want: 1.5},
+		{source: "0x1F", want: int64(31)},
+		{source: "0XfF", want: int64(255)},
+		{source: "0b101", want: int64(5)},
+		{source: "0o17", want: int64(15)},
+		{source: "1_000_000", want: int64(1000000)},
+		{source: "0xFF_FF", want: int64(65535)},
+		{source: "1e3", want: 1000.0},
+		{source: "1.5e-2", want: 0.015},
+		{source: "2E+2", want: 200.0},
+		{source: "12n", want: big.NewInt(12)},
+		{source: "9223372036854775808", want: 9223372036854775808.0},
+		{source: "0x_1", wantErr: "'_' must separate digits"},
+		{source: "1__0", wantErr: "'_' must separate digits"},
+		{source: "1_", wantErr: "'_' must separate digits"},
+		{source: "1_.5", wantErr: "'_' must separate digits"},
+		{source: "0b2", wantErr: "Invalid digit '2'"},
+		{source: "0o8", wantErr: "Invalid digit '8'"},
+		{source: "0xG", wantErr: "Invalid digit 'G'"},
+		{source: "1.2.3", wantErr: "Invalid number '1.2.3': too many decimal points"},
+		{source: "1.", wantErr: "Invalid number '1.': expected digits after '.'"},
+		{source: "1e", wantErr: "expected digits in exponent"},
+		{source: "1.5n", wantErr: "BigInt literals must be integers"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			token, err := NewScanner([]byte(tt.source)).NextToken()
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want one containing %q", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if token.Type != NUMBER {
+				t.Fatalf("got a %s token, want a number", token.Type.Type())
+			}
+
+			if want, ok := tt.want.(*big.Int); ok {
+				got, ok := token.Literal.(*big.Int)
+				if !ok || got.Cmp(want) != 0 {
+					t.Fatalf("got %#v, want %v", token.Literal, want)
+				}
+
+				return
+			}
+
+			if !reflect.DeepEqual(token.Literal, tt.want) {
+				t.Fatalf("got %#v, want %#v", token.Literal, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseNumber(t *testing.T) {
+	tests := []struct {
+		str  string
+		want interface{}
+		ok   bool
+	}{
+		{"12", int64(12), true},
+		{"0x1F", int64(31), true},
+		{"1_000.5", 1000.5, true},
+		{"1e3", 1000.0, true},
+		{"1.2.3", nil, false},
+		{"1.", nil, false},
+		{"1 2", nil, false},
+		{"1 // comment", nil, false},
+		{"1abc", nil, false},
+		{"abc", nil, false},
+		{"", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.str, func(t *testing.T) {
+			got, ok := parseNumber(tt.str)
+			if ok != tt.ok || !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got (%#v, %v), want (%#v, %v)", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/big"
+	"strings"
+)
+
+// Classes can customize how their instances are printed, compared and hashed by
+// defining the toString(), equals(other) and hash() methods. Instances of classes that
+// do not define them fall back to "Name instance" and identity.
+//
+// Operators are overloaded the same way: when the left operand of a binary operator is
+// an instance whose class defines the matching method below, the method is called with
+// the right operand. "-x" calls __neg(), "x[i]" calls __index(i) and "x[i] = v" calls
+// __setindex(i, v).
+
+var binaryOperatorHooks = map[TokenType]string{
+	PLUS:          "__add",
+	MINUS:         "__sub",
+	STAR:          "__mul",
+	SLASH:         "__div",
+	PERCENT:       "__mod",
+	LESS:          "__lt",
+	LESS_EQUAL:    "__le",
+	GREATER:       "__gt",
+	GREATER_EQUAL: "__ge",
+}
+
+// callHook calls the method name of an instance with args, as long as its class defines
+// such a method accepting that many arguments. The second return value reports whether
+// the hook was found.
+func callHook(ci *ClassInstance, name string, args ...interface{}) (interface{}, bool, error) {
+	m, ok := ci.findMethod(name)
+	if !ok {
+		return nil, false, nil
+	}
+
+	hook, ok := m.(Caller)
+	if !ok || !acceptsArgs(hook, len(args)) {
+		return nil, false, nil
+	}
+
+	ret, err := hook.Call(args...)
+	if err != nil {
+		return nil, true, err
+	}
+
+	return ret, true, nil
+}
+
+// Stringify returns the canonical representation of a runtime value, it is what print
+// outputs. Instances whose class defines a toString() method are converted by calling
+// it.
+func Stringify(val interface{}) (string, error) {
+	switch v := val.(type) {
+	case nil:
+		return "nil", nil
+	case bool:
+		if v {
+			return "true", nil
+		}
+
+		return "false", nil
+	case string:
+		return v, nil
+	case int64, float64:
+		return formatNumber(v), nil
+	case *big.Int:
+		return v.String(), nil
+	case *big.Rat:
+		return formatDecimal(v), nil
+	case *List:
+		return stringifyList(v, map[*List]bool{})
+	case *ClassInstance:
+		s, ok, err := callHook(v, "toString")
+		if err != nil {
+			return "", err
+		}
+
+		if !ok {
+			return v.String(), nil
+		}
+
+		str, ok := s.(string)
+		if !ok {
+			return "", &toStringError{class: v.Class.Name, val: s}
+		}
+
+		return str, nil
+	case fmt.Stringer:
+		return v.String(), nil
+	}
+
+	return fmt.Sprintf("%v", val), nil
+}
+
+// stringifyList converts a list and the lists nested in it, a list which contains
+// itself prints as "[...]" where it repeats. seen holds the lists being converted.
+func stringifyList(l *List, seen map[*List]bool) (string, error) {
+	if seen[l] {
+		return "[...]", nil
+	}
+
+	seen[l] = true
+	defer delete(seen, l)
+
+	elements := make([]string, 0, len(l.Elements))
+
+	for _, e := range l.Elements {
+		var s string
+		var err error
+
+		if inner, ok := e.(*List); ok {
+			s, err = stringifyList(inner, seen)
+		} else {
+			s, err = Stringify(e)
+		}
+
+		if err != nil {
+			return "", err
+		}
+
+		elements = append(elements, s)
+	}
+
+	return "[" + strings.Join(elements, ", ") + "]", nil
+}
+
+// toStringError reports a toString() method which did not return a string. It does not
+// know the line the instance was converted on, stringifyAt adds it.
+type toStringError struct {
+	class string
+	val   interface{}
+}
+
+func (e *toStringError) Error() string {
+	return fmt.Sprintf("%s.toString() must return a string, got %s.", e.class, typeName(e.val))
+}
+
+// stringifyAt is Stringify for a value converted on the given line, which a
+// toStringError is reported on.
+func stringifyAt(val interface{}, line int) (string, error) {
+	s, err := Stringify(val)
+	if _, ok := err.(*toStringError); ok {
+		return "", fmt.Errorf("%w\n[line %d]", err, line)
+	}
+
+	return s, err
+}
+
+// NativeStr converts any value to a string using Stringify.
+type NativeStr struct{}
+
+func (ns *NativeStr) Call(args ...interface{}) (interface{}, error) {
+	return Stringify(args[0])
+}
+
+func (ns *NativeStr) Arity() (int, int) { return 1, 1 }
+
+func (ns *NativeStr) String() string {
+	return "<native fn>"
+}
+
+// valuesEqual implements "==". An instance whose class defines __eq(other) or
+// equals(other) decides for itself, this is tried on the left operand first.
+func valuesEqual(leftVal, rightVal interface{}) (bool, error) {
+	for _, operands := range [][2]interface{}{{leftVal, rightVal}, {rightVal, leftVal}} {
+		ci, ok := operands[0].(*ClassInstance)
+		if !ok {
+			continue
+		}
+
+		for _, hook := range []string{"__eq", "equals"} {
+			eq, ok, err := callHook(ci, hook, operands[1])
+			if err != nil {
+				return false, err
+			}
+
+			if ok {
+				return isTrue(eq), nil
+			}
+		}
+	}
+
+	return isEqual(leftVal, rightVal), nil
+}
+
+// hashValue returns a hash consistent with valuesEqual: equal numbers of different kinds
+// hash the same, instances whose class defines hash() use it and every other instance
+// hashes by identity. Lists are compared by identity too, so their elements are never
+// visited.
+func hashValue(val interface{}) (int64, error) {
+	h := fnv.New64a()
+
+	switch v := val.(type) {
+	case *ClassInstance:
+		ret, ok, err := callHook(v, "hash")
+		if err != nil {
+			return 0, err
+		}
+
+		if !ok {
+			_, _ = fmt.Fprintf(h, "%p", v)
+			break
+		}
+
+		// mix the result of hash() rather than returning it, a class returning 1 would
+		// otherwise collide with every value whose hash happens to be 1
+		i, err := hashValue(ret)
+		if err != nil {
+			return 0, err
+		}
+
+		_, _ = fmt.Fprintf(h, "hash:%d", i)
+	case int64, float64, *big.Int, *big.Rat:
+		// hash the exact value so that 1, 1.0, 1n and Decimal(1) collide
+		r, ok := exactRat(v)
+		if !ok {
+			_, _ = fmt.Fprintf(h, "%v", math.Float64bits(toFloat(v)))
+			break
+		}
+
+		_, _ = fmt.Fprintf(h, "number:%s", r.RatString())
+	case *List:
+		_, _ = fmt.Fprintf(h, "%p", v)
+	default:
+		_, _ = fmt.Fprintf(h, "%T:%v", v, v)
+	}
+
+	return int64(h.Sum64()), nil
+}
+
+// NativeHash exposes hashValue to scripts.
+type NativeHash struct{}
+
+func (nh *NativeHash) Call(args ...interface{}) (interface{}, error) {
+	return hashValue(args[0])
+}
+
+func (nh *NativeHash) Arity() (int, int) { return 1, 1 }
+
+func (nh *NativeHash) String() string {
+	return "<native fn>"
+}
+
+// builtinValue is implemented by builtin values which have methods, like generators
+// and channels.
+type builtinValue interface {
+	method(name string) (Caller, bool)
+}
+
+// BuiltinMethod is a method of a builtin value, bound to the value.
+type BuiltinMethod struct {
+	MinArity int
+	MaxArity int
+
+	call func(args ...interface{}) (interface{}, error)
+}
+
+func (bm *BuiltinMethod) Call(args ...interface{}) (interface{}, error) {
+	return bm.call(args...)
+}
+
+func (bm *BuiltinMethod) Arity() (int, int) { return bm.MinArity, bm.MaxArity }
+
+func (bm *BuiltinMethod) String() string {
+	return "<native fn>"
+}
@@ -0,0 +1,134 @@
+package main
+
+import "testing"
+
+func TestStringifyList(t *testing.T) {
+	self := &List{Elements: []interface{}{int64(1)}}
+	self.Elements = append(self.Elements, self)
+
+	outer := &List{}
+	inner := &List{Elements: []interface{}{outer}}
+	outer.Elements = []interface{}{inner, "x"}
+
+	shared := &List{Elements: []interface{}{int64(1)}}
+
+	tests := []struct {
+		name string
+		val  *List
+		want string
+	}{
+		{"empty", &List{}, "[]"},
+		{"values", &List{Elements: []interface{}{int64(1), 2.5, "a", nil, true}}, "[1, 2.5, a, nil, true]"},
+		{"nested", &List{Elements: []interface{}{&List{Elements: []interface{}{int64(1)}}}}, "[[1]]"},
+		{"contains itself", self, "[1, [...]]"},
+		{"indirect cycle", outer, "[[[...]], x]"},
+		{"repeated but not cyclic", &List{Elements: []interface{}{shared, shared}}, "[[1], [1]]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Stringify(tt.val)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got != tt.want {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelfReferencingListEqualityAndHash(t *testing.T) {
+	l := &List{}
+	l.Elements = []interface{}{l}
+
+	eq, err := valuesEqual(l, l)
+	if err != nil || !eq {
+		t.Fatalf("got (%v, %v), want a list to equal itself", eq, err)
+	}
+
+	other := &List{Elements: []interface{}{l}}
+	if eq, err := valuesEqual(l, other); err != nil || eq {
+		t.Fatalf("got (%v, %v), want distinct lists to differ", eq, err)
+	}
+
+	h1, err := hashValue(l)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	h2, _ := hashValue(l)
+	if h1 != h2 {
+		t.Fatalf("got different hashes %d and %d for the same list", h1, h2)
+	}
+}
+
+func TestClassHooks(t *testing.T) {
+	runProgramTests(t, []programTest{
+		{
+			name: "toString",
+			source: `
+class P { init(x) { this.x = x; } toString() { return "P(" + str(this.x) + ")"; } }
+print P(1);
+print [P(2)];
+print str(P(3));
+`,
+			want: "P(1)\n[P(2)]\nP(3)\n",
+		},
+		{
+			name: "printed toString not returning a string",
+			source: `
+class A { toString() { return 1; } }
+print A();
+`,
+			wantErr: "A.toString() must return a string, got number.\n[line 3]",
+		},
+		{
+			name: "converted toString not returning a string",
+			source: `
+class A { toString() { return 1; } }
+var s =
+  str(A());
+`,
+			wantErr: "A.toString() must return a string, got number.\n[line 4]",
+		},
+		{
+			name: "unmatched toString not returning a string",
+			source: `
+class A { toString() { return 1; } }
+var m = match (A()) {
+  case 1 => 1;
+};
+`,
+			wantErr: "A.toString() must return a string, got number.\n[line 3]",
+		},
+		{
+			name: "equals",
+			source: `
+class P { init(x) { this.x = x; } equals(o) { return o.x == this.x; } }
+print P(1) == P(1);
+print P(1) != P(2);
+`,
+			want: "true\ntrue\n",
+		},
+		{
+			name: "hash is consistent",
+			source: `
+class P { init(x) { this.x = x; } hash() { return this.x; } }
+print hash(P(1)) == hash(P(1));
+print hash(P(1)) == hash(P(2));
+`,
+			want: "true\nfalse\n",
+		},
+		{
+			name: "hash is mixed",
+			source: `
+class P { hash() { return 1; } }
+print hash(P()) == 1;
+print hash(P()) == hash(1);
+`,
+			want: "false\nfalse\n",
+		},
+	})
+}